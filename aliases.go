@@ -0,0 +1,143 @@
+package nordlead3
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// A name for some bits of a patch data field, usually a spare one whose meaning has been worked out.
+// Get and Set take the name as they would a parameter, and predicates and sheets may use it, but the
+// layout of the data is untouched, so dumps encode exactly as before.
+type FieldAlias struct {
+	Name  string // the parameter name, e.g. "Midi_clock_source"
+	Path  string // the field holding the bits, e.g. "Spare7"
+	Shift int    // the lowest of the bits, 0 being the least significant
+	Bits  int    // how many bits, or 0 for all of them above Shift
+}
+
+var aliases = struct {
+	sync.RWMutex
+	byType map[reflect.Type]map[string]FieldAlias
+}{byType: make(map[reflect.Type]map[string]FieldAlias)}
+
+// Lists the aliases registered for the patch type, by name.
+func Aliases(pt PatchType) []FieldAlias {
+	aliases.RLock()
+	defer aliases.RUnlock()
+
+	var result []FieldAlias
+	for _, alias := range aliases.byType[dataType(pt)] {
+		result = append(result, alias)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Registers the alias for patches of the given type, replacing any alias of the same name. The name must
+// not be a parameter already, and the bits must lie within an unsigned field or switch.
+func RegisterAlias(pt PatchType, alias FieldAlias) error {
+	rt := dataType(pt)
+	root := reflect.New(rt).Elem()
+
+	if alias.Name == "" || strings.ContainsAny(alias.Name, ".[]") {
+		return ErrInvalidName
+	}
+	if _, _, err := lookupParameter(root, alias.Name); err == nil {
+		return ErrAliasTaken
+	}
+	rf, _, err := lookupParameter(root, alias.Path)
+	if err != nil {
+		return err
+	}
+	width := fieldWidth(rt, alias.Path)
+	if alias.Bits == 0 {
+		alias.Bits = width - alias.Shift
+	}
+	if rf.Kind() == reflect.Int || alias.Shift < 0 || alias.Bits <= 0 || alias.Shift+alias.Bits > width {
+		return ErrInvalidValue
+	}
+
+	aliases.Lock()
+	defer aliases.Unlock()
+	if aliases.byType[rt] == nil {
+		aliases.byType[rt] = make(map[string]FieldAlias)
+	}
+	aliases.byType[rt][alias.Name] = alias
+	return nil
+}
+
+// Forgets the alias of that name for the patch type, if there is one.
+func RemoveAlias(pt PatchType, name string) {
+	aliases.Lock()
+	defer aliases.Unlock()
+	delete(aliases.byType[dataType(pt)], name)
+}
+
+// helpers
+
+func dataType(pt PatchType) reflect.Type {
+	if pt == PerformanceT {
+		return reflect.TypeOf(PerformanceData{})
+	}
+	return reflect.TypeOf(ProgramData{})
+}
+
+// Resolves a path ending in an alias, e.g. "Patch_data_b.Midi_clock_source", to the field holding its
+// bits and the alias itself.
+func lookupAlias(root reflect.Value, path string) (reflect.Value, FieldAlias, bool) {
+	rv := root
+	prefix := ""
+	name := path
+	if dot := strings.LastIndex(path, "."); dot >= 0 {
+		prefix, name = path[:dot+1], path[dot+1:]
+		for _, part := range strings.Split(path[:dot], ".") {
+			sf, ok := rv.Type().FieldByName(part)
+			if !ok || sf.Type.Kind() != reflect.Struct {
+				return reflect.Value{}, FieldAlias{}, false
+			}
+			rv = rv.FieldByIndex(sf.Index)
+		}
+	}
+
+	aliases.RLock()
+	alias, ok := aliases.byType[rv.Type()][name]
+	aliases.RUnlock()
+	if !ok {
+		return reflect.Value{}, FieldAlias{}, false
+	}
+	rf, _, err := lookupParameter(root, prefix+alias.Path)
+	return rf, alias, err == nil
+}
+
+// The number of bits the field at path, which must exist, takes in a dump. This is the len of its tag,
+// which may hold more than the field's max.
+func fieldWidth(rt reflect.Type, path string) int {
+	var sf reflect.StructField
+	for _, part := range strings.Split(path, ".") {
+		name, _, _ := splitElement(part)
+		sf, _ = rt.FieldByName(name)
+		rt = sf.Type
+	}
+	width, _ := strconv.Atoi(sf.Tag.Get("len"))
+	return width
+}
+
+func (alias FieldAlias) mask() int {
+	return 1<<uint(alias.Bits) - 1
+}
+
+func (alias FieldAlias) get(rf reflect.Value) int {
+	return fieldValue(rf) >> uint(alias.Shift) & alias.mask()
+}
+
+func (alias FieldAlias) set(rf reflect.Value, value int) {
+	if rf.Kind() == reflect.Bool {
+		rf.SetBool(value == 1)
+		return
+	}
+	others := fieldValue(rf) &^ (alias.mask() << uint(alias.Shift))
+	setNumber(rf, others|value<<uint(alias.Shift))
+}
@@ -0,0 +1,84 @@
+package nordlead3
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAliasGetAndSet(t *testing.T) {
+	if err := RegisterAlias(PerformanceT, FieldAlias{Name: "Spare7_high", Path: "Spare7", Shift: 4}); err != nil {
+		t.Fatal(err)
+	}
+	defer RemoveAlias(PerformanceT, "Spare7_high")
+	if err := RegisterAlias(ProgramT, FieldAlias{Name: "Spare10_low", Path: "Spare10", Bits: 1}); err != nil {
+		t.Fatal(err)
+	}
+	defer RemoveAlias(ProgramT, "Spare10_low")
+
+	performance := NewInitPerformance()
+	performance.data.Spare7 = 0x05
+	if err := performance.Set("Spare7_high", 0x0A); err != nil || performance.data.Spare7 != 0xA5 {
+		t.Errorf("Expected the high bits of Spare7 to be set, got %#x (%v)", performance.data.Spare7, err)
+	}
+	if value, err := performance.Get("Spare7_high"); err != nil || value != 0x0A {
+		t.Errorf("Expected 0x0A, got %#x (%v)", value, err)
+	}
+	if err := performance.Set("Spare7_high", 16); err == nil {
+		t.Errorf("Expected a value wider than the alias to be rejected")
+	}
+	if err := performance.Set("Patch_data_b.Spare10_low", 1); err != nil || performance.data.Patch_data_b.Spare10 != 15 {
+		t.Errorf("Expected a program alias to reach into a slot (%v)", err)
+	}
+
+	direct := NewInitPerformance()
+	direct.data.Spare7 = 0xA5
+	direct.data.Patch_data_b.Spare10 |= 1
+	aliased, _ := performance.data.dumpSysex()
+	expected, _ := direct.data.dumpSysex()
+	if !reflect.DeepEqual(aliased, expected) {
+		t.Errorf("Expected setting through aliases to dump the same sysex as setting the fields")
+	}
+
+	memory := new(PatchMemory)
+	memory.set(patchRef{PerformanceT, MemoryT, index(0, 3)}, performance)
+	if found, err := memory.FindPerformances(PerformanceQuery{Where: "Spare7_high == 10"}); err != nil || len(found) != 1 {
+		t.Errorf("Expected predicates to take aliases, got %v (%v)", found, err)
+	}
+}
+
+func TestRegisterAliasErrors(t *testing.T) {
+	cases := []struct {
+		alias    FieldAlias
+		expected error
+	}{
+		{FieldAlias{Name: "Spare6", Path: "Spare7"}, ErrAliasTaken},
+		{FieldAlias{Name: "Slot.thing", Path: "Spare7"}, ErrInvalidName},
+		{FieldAlias{Name: "Nowhere", Path: "Spare99"}, ErrUnknownParameter},
+		{FieldAlias{Name: "Too_wide", Path: "Spare7", Shift: 6, Bits: 3}, ErrInvalidValue},
+		{FieldAlias{Name: "Signed", Path: "Patch_data_a.Wheel_morph_params.Oscmix", Bits: 1}, ErrInvalidValue},
+	}
+	for _, c := range cases {
+		if err := RegisterAlias(PerformanceT, c.alias); err != c.expected {
+			t.Errorf("%+v: expected %v, got %v", c.alias, c.expected, err)
+		}
+	}
+	if aliases := Aliases(PerformanceT); len(aliases) != 0 {
+		t.Errorf("Expected nothing to be registered, got %v", aliases)
+	}
+}
+
+func TestAliasCoversWholeField(t *testing.T) {
+	// Sub_arp_mode takes 4 bits, though its max of 4 only needs 3
+	if err := RegisterAlias(ProgramT, FieldAlias{Name: "Sub_arp_high", Path: "Sub_arp_mode", Shift: 1}); err != nil {
+		t.Fatal(err)
+	}
+	defer RemoveAlias(ProgramT, "Sub_arp_high")
+
+	if aliases := Aliases(ProgramT); len(aliases) != 1 || aliases[0].Bits != 3 {
+		t.Fatalf("Expected the alias to reach the top bit of the field, got %v", aliases)
+	}
+	program := NewInitProgram()
+	if err := program.Set("Sub_arp_high", 4); err != nil || program.data.Sub_arp_mode != 8 {
+		t.Errorf("Expected the top bit to be set, got %d (%v)", program.data.Sub_arp_mode, err)
+	}
+}
@@ -0,0 +1,60 @@
+package nordlead3
+
+import (
+	"strings"
+	"unicode"
+)
+
+// The tag may end one column short of the full width: a few factory names have a stray trailing space.
+const authorColumn = 15
+
+// Patch names follow the convention of ending with the author's tag as a separate, all-caps token
+// right-aligned to the end of the 16 characters (e.g. "Blade run    ZON"). A trailing token which does
+// not reach the end, as in "Organ B3", is part of the title, and such names have no author.
+func splitAuthor(name [16]byte) (title string, author string) {
+	trimmed := strings.TrimRight(strings.Replace(string(name[:]), "\x00", " ", -1), " ")
+	lastSpace := strings.LastIndex(trimmed, " ")
+
+	if lastSpace < 0 || len(trimmed) < authorColumn {
+		return trimmed, ""
+	}
+
+	title = strings.TrimRight(trimmed[:lastSpace], " ")
+	author = trimmed[lastSpace+1:]
+	if title == "" || !validAuthor(author) {
+		return trimmed, ""
+	}
+	return title, author
+}
+
+// Composes a name with the author right-aligned, as the factory patches do, truncating the title if
+// there is not enough room for both.
+func stampAuthor(title string, author string) (string, error) {
+	if !validAuthor(author) || len(author) > 14 {
+		return "", ErrInvalidAuthor
+	}
+
+	titleWidth := 16 - len(author) - 1
+	if len(title) > titleWidth {
+		title = strings.TrimRight(title[:titleWidth], " ")
+	}
+	if title == "" {
+		return "", ErrInvalidName
+	}
+	return title + strings.Repeat(" ", 16-len(title)-len(author)) + author, nil
+}
+
+func validAuthor(author string) bool {
+	hasUpper := false
+
+	for _, char := range author {
+		switch {
+		case unicode.IsUpper(char):
+			hasUpper = true
+		case unicode.IsDigit(char):
+		default:
+			return false
+		}
+	}
+	return hasUpper
+}
@@ -0,0 +1,107 @@
+package nordlead3
+
+// Settings for ComposePerformance. The zero value composes a performance named as NewInitPerformance's,
+// with its channels and split, enabling every slot given a program and focusing slot A.
+type ComposeOptions struct {
+	Destination   MemoryLocation // the performance location to store the result at, which must be blank
+	Name          string         // NewInitPerformance's if blank
+	Enabled       SlotMask       // the slots to enable; none enables every slot given a program
+	Focused       int            // the slot with the focus, 0 to 3 for A to D
+	MidiChannels  []uint         // one per slot, 0-16 as PerformanceData holds them; nil keeps NewInitPerformance's
+	AudioChannels []uint         // one per slot, 0-5; nil keeps NewInitPerformance's
+	Split         bool           // splits the keyboard at SplitKey
+	SplitKey      uint           // 0-127; zero keeps NewInitPerformance's
+}
+
+// Builds a performance from the programs at a, b, c and d, which go into slots A to D, and stores it at
+// opts.Destination. A slot whose location is blank keeps NewInitPerformance's program and is left disabled,
+// unless opts.Enabled names it, which is an error. This is a single operation for Undo.
+func (memory *PatchMemory) ComposePerformance(a, b, c, d MemoryLocation, opts ComposeOptions) error {
+	ref := patchRef{PerformanceT, MemoryT, opts.Destination.index()}
+	if !valid(ref.patchType, ref.source, ref.index) {
+		return ErrInvalidLocation
+	}
+	if err := opts.validate(); err != nil {
+		return err
+	}
+
+	performance := NewInitPerformance()
+	if opts.Name != "" {
+		if err := performance.SetName(opts.Name); err != nil {
+			return err
+		}
+	}
+
+	var given SlotMask
+	for slot, ml := range []MemoryLocation{a, b, c, d} {
+		program, err := memory.GetProgram(ml)
+		switch {
+		case err == ErrUninitialized:
+			continue
+		case err != nil:
+			return err
+		}
+		performance.setSlotProgram(slot, program, ml)
+		given.Set(slot)
+	}
+	if opts.Enabled&^given != 0 {
+		return ErrUninitialized
+	}
+	opts.apply(performance.data, given)
+
+	return memory.record(describe("compose", ref), func() error {
+		if memory.initialized(ref) {
+			return ErrMemoryOccupied
+		}
+		memory.put(ref, performance)
+		return nil
+	})
+}
+
+// helpers
+
+func (opts *ComposeOptions) validate() error {
+	if opts.Enabled > 0x0F || opts.Focused < 0 || opts.Focused >= NumSlots || opts.SplitKey > 127 {
+		return ErrInvalidValue
+	}
+	for _, channels := range []struct {
+		values []uint
+		max    uint
+	}{{opts.MidiChannels, 16}, {opts.AudioChannels, 5}} {
+		if channels.values == nil {
+			continue
+		}
+		if len(channels.values) != NumSlots {
+			return ErrInvalidValue
+		}
+		for _, value := range channels.values {
+			if value > channels.max {
+				return ErrInvalidValue
+			}
+		}
+	}
+	return nil
+}
+
+func (opts *ComposeOptions) apply(data *PerformanceData, given SlotMask) {
+	data.Enabled_slots = given
+	if opts.Enabled != 0 {
+		data.Enabled_slots = opts.Enabled
+	}
+	data.Focused_slot = uint(opts.Focused)
+
+	for slot := 0; slot < NumSlots; slot++ {
+		view := data.Slot(slot)
+		if opts.MidiChannels != nil {
+			*view.MidiChannel = opts.MidiChannels[slot]
+		}
+		if opts.AudioChannels != nil {
+			*view.AudioChannel = opts.AudioChannels[slot]
+		}
+	}
+
+	data.Splitpoint_enable = opts.Split
+	if opts.SplitKey != 0 {
+		data.Splitpoint_key = opts.SplitKey
+	}
+}
@@ -0,0 +1,75 @@
+package nordlead3
+
+import (
+	"testing"
+)
+
+func TestComposePerformance(t *testing.T) {
+	memory := populatedMemory(t, "Program-BladeRun     ZON-1.18.syx")
+	blade := MemoryLocation{validProgramBank, validProgramLocation}
+	memory.set(patchRef{ProgramT, MemoryT, index(0, 0)}, NewInitProgram())
+	blank := MemoryLocation{0, 1}
+	dest := MemoryLocation{1, 7}
+
+	opts := ComposeOptions{
+		Destination:   dest,
+		Name:          "Layered",
+		Focused:       1,
+		MidiChannels:  []uint{1, 1, 2, 3},
+		AudioChannels: []uint{0, 1, 2, 3},
+		Split:         true,
+		SplitKey:      60,
+	}
+	if err := memory.ComposePerformance(blade, MemoryLocation{0, 0}, blade, blank, opts); err != nil {
+		t.Fatal(err)
+	}
+	performance, err := memory.GetPerformance(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := performance.data
+	if performance.PrintableName() != "Layered         " || data.Enabled_slots != 0x07 || data.Focused_slot != 1 {
+		t.Errorf("Expected the name, enabled slots and focus to be set, got %q, %#x, %d", performance.PrintableName(), data.Enabled_slots, data.Focused_slot)
+	}
+	if data.Midi_channel_slot_b != 1 || data.Audio_channel_slot_d != 3 || !data.Splitpoint_enable || data.Splitpoint_key != 60 {
+		t.Errorf("Expected the channels and split to be set")
+	}
+	if program, _ := performance.SlotProgram(2); program.PrintableName() != validProgramName || data.Bank_slot_c != validProgramBank || data.Program_slot_c != validProgramLocation {
+		t.Errorf("Expected slot C to hold and refer to Blade run")
+	}
+	if data.Patch_data_d != NewInitPerformance().data.Patch_data_d {
+		t.Errorf("Expected the blank slot to keep the init program")
+	}
+
+	if description, _ := memory.Undo(); description != "compose performance 1:007" || memory.NumPerformances(true) != 0 {
+		t.Errorf("Expected Undo to remove the performance, got %q", description)
+	}
+}
+
+func TestComposePerformanceErrors(t *testing.T) {
+	memory := populatedMemory(t, "Program-BladeRun     ZON-1.18.syx")
+	blade := MemoryLocation{validProgramBank, validProgramLocation}
+	blank := MemoryLocation{0, 0}
+	memory.set(patchRef{PerformanceT, MemoryT, index(0, 1)}, NewInitPerformance())
+
+	cases := []struct {
+		opts     ComposeOptions
+		expected error
+	}{
+		{ComposeOptions{Destination: MemoryLocation{0, 1}}, ErrMemoryOccupied},
+		{ComposeOptions{Destination: MemoryLocation{NumPerformanceBanks, 0}}, ErrInvalidLocation},
+		{ComposeOptions{Enabled: 0x03}, ErrUninitialized},
+		{ComposeOptions{Focused: NumSlots}, ErrInvalidValue},
+		{ComposeOptions{MidiChannels: []uint{1, 2}}, ErrInvalidValue},
+		{ComposeOptions{AudioChannels: []uint{0, 0, 0, 6}}, ErrInvalidValue},
+	}
+	for _, c := range cases {
+		if err := memory.ComposePerformance(blade, blank, blank, blank, c.opts); err != c.expected {
+			t.Errorf("%+v: expected %v, got %v", c.opts, c.expected, err)
+		}
+	}
+	if memory.NumPerformances(true) != 1 {
+		t.Errorf("Expected nothing to be stored")
+	}
+}
@@ -0,0 +1,165 @@
+package nordlead3
+
+import (
+	"fmt"
+	"strings"
+)
+
+// conflictActions: what to do with a patch whose destination already holds one
+const (
+	ConflictAbort          ConflictAction = iota // stop: imports end there, transfers are undone
+	ConflictOverwrite                            // replace the patch at the destination
+	ConflictSkip                                 // leave the destination alone and carry on with the next patch
+	ConflictNextFreeInBank                       // use the first blank location after the destination in its bank, wrapping around
+	ConflictNextFree                             // use the first blank location after the destination, in any bank
+	ConflictRename                               // as ConflictNextFree, numbering the name ("Name 2") if another patch carries it
+	ConflictKeepNewer                            // overwrite only if the incoming patch is for a newer OS version
+)
+
+type ConflictAction int
+
+// A ConflictPolicy decides what happens when a patch is imported, moved or copied onto an occupied location.
+// Any ConflictAction is a policy which always takes that action; a ConflictFunc can decide case by case.
+type ConflictPolicy interface {
+	Resolve(conflict Conflict) ConflictAction
+}
+
+// Lets interactive tools ask the user, e.g. ConflictFunc(func(c Conflict) ConflictAction { ... }).
+type ConflictFunc func(conflict Conflict) ConflictAction
+
+// An occupied destination, as presented to a ConflictPolicy.
+type Conflict struct {
+	Destination PatchLocation
+	PatchType   PatchType
+	Incoming    string // Summary of the patch being placed
+	Existing    string // Summary of the patch already there
+}
+
+var conflictActionNames = []string{"abort", "overwrite", "skip", "next free in bank", "next free", "rename", "keep newer"}
+
+func (action ConflictAction) Resolve(conflict Conflict) ConflictAction {
+	return action
+}
+
+func (action ConflictAction) String() string {
+	return enumString(conflictActionNames, uint(action))
+}
+
+func (f ConflictFunc) Resolve(conflict Conflict) ConflictAction {
+	return f(conflict)
+}
+
+// The policy matching the overwrite flag the import functions used to take.
+func overwritePolicy(overwrite bool) ConflictPolicy {
+	if overwrite {
+		return ConflictOverwrite
+	}
+	return ConflictSkip
+}
+
+// helpers
+
+// Sets the patch at dest or, if dest is occupied, wherever the policy directs. Returns the location used
+// and whether a patch there was replaced. ErrMemoryOccupied means the policy skipped the patch, and
+// ErrConflictAborted that it asked to stop.
+func (memory *PatchMemory) setResolved(incoming patch, dest patchRef, policy ConflictPolicy) (patchRef, bool, error) {
+	if !dest.valid() {
+		return dest, false, ErrInvalidLocation
+	}
+	if !memory.initialized(dest) {
+		return dest, false, memory.set(dest, incoming)
+	}
+
+	existing, _ := memory.get(dest)
+	action := policy.Resolve(Conflict{dest.patchLocation(), dest.patchType, incoming.Summary(), existing.Summary()})
+
+	switch action {
+	case ConflictOverwrite:
+		return dest, true, memory.set(dest, incoming)
+	case ConflictKeepNewer:
+		if versionX100(incoming.Version()) <= versionX100(existing.Version()) {
+			return dest, false, ErrMemoryOccupied
+		}
+		return dest, true, memory.set(dest, incoming)
+	case ConflictNextFreeInBank, ConflictNextFree, ConflictRename:
+		free, err := memory.nextFree(dest, action == ConflictNextFreeInBank)
+		if err != nil {
+			return dest, false, err
+		}
+		if action == ConflictRename {
+			if incoming, err = memory.renameDuplicate(incoming); err != nil {
+				return dest, false, err
+			}
+		}
+		return free, false, memory.set(free, incoming)
+	case ConflictAbort:
+		return dest, false, ErrConflictAborted
+	default:
+		return dest, false, ErrMemoryOccupied
+	}
+}
+
+// Returns the first blank location after dest, wrapping around within its bank or, if inBank is false,
+// within all of memory (or all of the slots).
+func (memory *PatchMemory) nextFree(dest patchRef, inBank bool) (patchRef, error) {
+	first, count := 0, 0
+	for valid(dest.patchType, dest.source, count) {
+		count++
+	}
+	if inBank && dest.source == MemoryT {
+		first, count = index(dest.bank(), 0), BankSize
+	}
+
+	for i := 1; i < count; i++ {
+		ref := patchRef{dest.patchType, dest.source, first + (dest.index-first+i)%count}
+		if !memory.initialized(ref) {
+			return ref, nil
+		}
+	}
+	return dest, ErrMemoryOverflow
+}
+
+// Returns the patch, or if another patch of its type in memory carries the same name, a copy of it
+// named "Name 2" (or 3, 4...). The author tag stays at the end of the name. A patch being moved is still
+// at its source, which does not count as another patch.
+func (memory *PatchMemory) renameDuplicate(incoming patch) (patch, error) {
+	names := make(map[string]bool)
+	for _, ref := range memory.initializedRefs(incoming.PatchType()) {
+		if existing, _ := memory.get(ref); existing != incoming {
+			names[existing.PrintableName()] = true
+		}
+	}
+	if !names[incoming.PrintableName()] {
+		return incoming, nil
+	}
+
+	title, author := incoming.Title(), incoming.Author()
+	width := 16
+	if author != "" {
+		width -= len(author) + 1
+	}
+	for n := 2; ; n++ {
+		suffix := fmt.Sprintf(" %d", n)
+		if len(suffix) >= width {
+			return nil, ErrInvalidName
+		}
+		name := strings.TrimRight(title[:min(len(title), width-len(suffix))], " ") + suffix
+		if author != "" {
+			name, _ = stampAuthor(name, author)
+		}
+		if !names[fmt.Sprintf("%-16s", name)] {
+			renamed := clonePatch(incoming)
+			return renamed, renamed.SetName(name)
+		}
+	}
+}
+
+func clonePatch(p patch) patch {
+	switch p := p.(type) {
+	case *Performance:
+		return p.clone()
+	case *Program:
+		return p.clone()
+	}
+	return p
+}
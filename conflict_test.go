@@ -0,0 +1,196 @@
+package nordlead3
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestImportConflictActions(t *testing.T) {
+	elektro := helperLoadBytes(t, "Program-Elektro         -1.20.syx")
+	at := MemoryLocation{validProgramBank, validProgramLocation}
+	next := PatchLocation{MemoryT, MemoryLocation{validProgramBank, validProgramLocation + 1}}
+
+	cases := []struct {
+		policy      ConflictPolicy
+		outcome     ImportOutcome
+		destination PatchLocation
+		name        string // of the program at the destination afterwards
+	}{
+		{ConflictSkip, OutcomeSkippedOccupied, PatchLocation{MemoryT, at}, validProgramName},
+		{ConflictOverwrite, OutcomeOverwritten, PatchLocation{MemoryT, at}, "ElekTro       PG"},
+		{ConflictNextFreeInBank, OutcomeImported, next, "ElekTro       PG"},
+		{ConflictNextFree, OutcomeImported, next, "ElekTro       PG"},
+		{ConflictRename, OutcomeImported, next, "ElekTro       PG"}, // no other patch carries the name
+		{ConflictKeepNewer, OutcomeOverwritten, PatchLocation{MemoryT, at}, "ElekTro       PG"},
+		{ConflictAbort, OutcomeSkippedOccupied, PatchLocation{MemoryT, at}, validProgramName},
+	}
+
+	for _, c := range cases {
+		memory := populatedMemory(t, "Program-BladeRun     ZON-1.18.syx")
+		report, _ := memory.ImportTo(bytes.NewReader(elektro), ProgramT, at, c.policy)
+
+		entry := report.Entries[0]
+		if entry.Outcome != c.outcome || entry.Destination != c.destination {
+			t.Errorf("%v: expected %s at %s, got %s at %s", c.policy, c.outcome, c.destination, entry.Outcome, entry.Destination)
+		}
+		if program, err := memory.GetProgram(c.destination.MemoryLocation); err != nil || program.PrintableName() != c.name {
+			t.Errorf("%v: expected %q at %s, got %v (%v)", c.policy, c.name, c.destination, program, err)
+		}
+	}
+}
+
+func TestImportToContinuesAfterPlacedPatch(t *testing.T) {
+	memory := populatedMemory(t, "Program-BladeRun     ZON-1.18.syx")
+	at := MemoryLocation{validProgramBank, validProgramLocation}
+	memory.copy(patchRef{ProgramT, MemoryT, at.index()}, patchRef{ProgramT, MemoryT, at.index() + 1})
+	conflicts := 0
+	policy := ConflictFunc(func(c Conflict) ConflictAction {
+		conflicts++
+		if conflicts == 1 {
+			return ConflictNextFree
+		}
+		return ConflictSkip
+	})
+
+	elektro := helperLoadBytes(t, "Program-Elektro         -1.20.syx")
+	report, _ := memory.ImportTo(bytes.NewReader(append(elektro, elektro...)), ProgramT, at, policy)
+	second := PatchLocation{MemoryT, MemoryLocation{validProgramBank, validProgramLocation + 3}}
+	if conflicts != 1 || report.Entries[1].Outcome != OutcomeImported || report.Entries[1].Destination != second {
+		t.Errorf("Expected the second patch to follow the first to %s, got %s", second, report)
+	}
+}
+
+func TestImportKeepsNewerOnly(t *testing.T) {
+	memory := new(PatchMemory)
+	at := MemoryLocation{0, 0}
+	memory.ImportTo(bytes.NewReader(helperLoadBytes(t, "Program-Elektro         -1.20.syx")), ProgramT, at, ConflictAbort)
+
+	report, _ := memory.ImportTo(bytes.NewReader(validProgramSysex(t)), ProgramT, at, ConflictKeepNewer)
+	if report.Entries[0].Outcome != OutcomeSkippedOccupied {
+		t.Errorf("Expected the 1.18 program not to replace the 1.20 one, got %s", report)
+	}
+}
+
+func TestImportRenamesDuplicates(t *testing.T) {
+	memory := populatedMemory(t, "Program-BladeRun     ZON-1.18.syx")
+	stream := append(validProgramSysex(t), validProgramSysex(t)...)
+
+	report, _ := memory.Import(bytes.NewReader(stream), ConflictRename)
+	expected := []string{"Blade run 2  ZON", "Blade run 3  ZON"}
+	for i, entry := range report.Entries {
+		program, err := memory.GetProgram(entry.Destination.MemoryLocation)
+		if err != nil || program.PrintableName() != expected[i] || program.Author() != "ZON" {
+			t.Errorf("Expected %q at %s, got %v (%v)", expected[i], entry.Destination, program, err)
+		}
+	}
+	if original, _ := memory.GetProgram(MemoryLocation{validProgramBank, validProgramLocation}); original.PrintableName() != validProgramName {
+		t.Errorf("Renaming changed the original to %q", original.PrintableName())
+	}
+}
+
+func TestNextFreeWrapsAround(t *testing.T) {
+	memory := new(PatchMemory)
+	last := patchRef{ProgramT, MemoryT, index(2, BankSize-1)}
+	memory.set(last, NewInitProgram())
+
+	if ref, _ := memory.nextFree(last, true); ref != (patchRef{ProgramT, MemoryT, index(2, 0)}) {
+		t.Errorf("Expected the bank to wrap around to 2:000, got %s", ref.String())
+	}
+	if ref, _ := memory.nextFree(last, false); ref != (patchRef{ProgramT, MemoryT, index(3, 0)}) {
+		t.Errorf("Expected the next bank, got %s", ref.String())
+	}
+
+	slot := performanceSlotRef
+	memory.set(slot, NewInitPerformance())
+	if _, err := memory.nextFree(slot, false); err != ErrMemoryOverflow {
+		t.Errorf("Expected ErrMemoryOverflow with the only slot taken, got %v", err)
+	}
+}
+
+func TestImportAbortsAndAsks(t *testing.T) {
+	memory := populatedMemory(t, "Program-BladeRun     ZON-1.18.syx")
+	stream := append(validProgramSysex(t), helperLoadBytes(t, "Program-Elektro         -1.20.syx")...)
+
+	report, err := memory.Import(bytes.NewReader(stream), ConflictAbort)
+	if err != ErrConflictAborted || len(report.Entries) != 1 {
+		t.Errorf("Expected the import to stop at the first conflict, got %v:\n%s", err, report)
+	}
+
+	var conflicts []Conflict
+	ask := ConflictFunc(func(c Conflict) ConflictAction {
+		conflicts = append(conflicts, c)
+		return ConflictOverwrite
+	})
+	memory.Import(bytes.NewReader(stream), ask)
+	if len(conflicts) != 1 || conflicts[0].Destination != (PatchLocation{MemoryT, MemoryLocation{validProgramBank, validProgramLocation}}) || conflicts[0].PatchType != ProgramT {
+		t.Errorf("Expected to be asked about the one occupied location, got %+v", conflicts)
+	}
+}
+
+func TestMoveConflictPolicies(t *testing.T) {
+	src := []MemoryLocation{{0, 0}, {0, 1}}
+	dest := MemoryLocation{1, 0}
+	setup := func() *PatchMemory {
+		memory := new(PatchMemory)
+		for _, ml := range append(src, MemoryLocation{1, 1}) {
+			program := NewInitProgram()
+			program.SetName(fmt.Sprintf("%d:%d", ml.Bank, ml.Location))
+			memory.set(patchRef{ProgramT, MemoryT, ml.index()}, program)
+		}
+		return memory
+	}
+
+	memory := setup()
+	if err := memory.MovePrograms(src, dest, ConflictAbort); err != ErrMemoryOccupied {
+		t.Errorf("Expected ErrMemoryOccupied, got %v", err)
+	}
+	if !memory.initialized(patchRef{ProgramT, MemoryT, 0}) || memory.initialized(patchRef{ProgramT, MemoryT, dest.index()}) {
+		t.Errorf("An aborted move was not undone")
+	}
+
+	memory = setup()
+	memory.MovePrograms(src, dest, ConflictSkip)
+	if program, _ := memory.GetProgram(MemoryLocation{0, 1}); program == nil {
+		t.Errorf("A skipped program should stay where it was")
+	}
+	if program, _ := memory.GetProgram(MemoryLocation{1, 0}); program == nil || program.Title() != "0:0" {
+		t.Errorf("Expected 0:0 to be moved to 1:0, got %v", program)
+	}
+
+	memory = setup()
+	memory.MovePrograms(src, dest, ConflictNextFreeInBank)
+	if program, _ := memory.GetProgram(MemoryLocation{1, 2}); program == nil || program.Title() != "0:1" || memory.initialized(patchRef{ProgramT, MemoryT, 1}) {
+		t.Errorf("Expected 0:1 to be moved to 1:2, got %v", program)
+	}
+}
+
+func TestMoveRenamesOnlyDuplicates(t *testing.T) {
+	setup := func() *PatchMemory {
+		memory := new(PatchMemory)
+		for location := 0; location < BankSize; location++ {
+			program := NewInitProgram()
+			program.SetName(fmt.Sprintf("Fill %d", location))
+			memory.set(patchRef{ProgramT, MemoryT, index(0, location)}, program)
+		}
+		program, _ := memory.GetProgram(MemoryLocation{0, 0})
+		program.SetName("Welcome")
+		return memory
+	}
+
+	memory := setup()
+	if err := memory.MovePrograms([]MemoryLocation{{0, 0}}, MemoryLocation{0, 5}, ConflictRename); err != nil {
+		t.Fatal(err)
+	}
+	if program, _ := memory.GetProgram(MemoryLocation{1, 0}); program == nil || program.Title() != "Welcome" || memory.initialized(patchRef{ProgramT, MemoryT, 0}) {
+		t.Errorf("Expected Welcome to be moved to 1:000 under its own name, got %v", program)
+	}
+
+	memory = setup()
+	if err := memory.transfer([]patchRef{{ProgramT, MemoryT, 0}}, patchRef{ProgramT, MemoryT, 5}, copyM, ConflictRename); err != nil {
+		t.Fatal(err)
+	}
+	if program, _ := memory.GetProgram(MemoryLocation{1, 0}); program == nil || program.Title() != "Welcome 2" {
+		t.Errorf("Expected the copy of Welcome at 1:000 to be renamed, got %v", program)
+	}
+}
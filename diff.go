@@ -0,0 +1,66 @@
+package nordlead3
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// A parameter which differs between two patches, with its values as Get returns them.
+type ParamChange struct {
+	Path string
+	Old  int
+	New  int
+	typ  reflect.Type // of the field, to name enum values
+}
+
+// Lists the parameters of the performances which differ, in the order Parameters lists them. This
+// includes those of the programs in each slot, e.g. "Patch_data_c.Wheel_morph_params.Filt_frequency1".
+// Names and versions are not parameters, so they are not compared.
+func DiffPerformances(a *Performance, b *Performance) ([]ParamChange, error) {
+	if a == nil || b == nil {
+		return nil, ErrUninitialized
+	}
+	return diffParameters(reflect.ValueOf(a.data).Elem(), reflect.ValueOf(b.data).Elem(), a.Parameters()), nil
+}
+
+// Lists the parameters of the programs which differ, in the order Parameters lists them. Names,
+// categories and versions are not parameters, so they are not compared.
+func DiffPrograms(a *Program, b *Program) ([]ParamChange, error) {
+	if a == nil || b == nil {
+		return nil, ErrUninitialized
+	}
+	return diffParameters(reflect.ValueOf(a.data).Elem(), reflect.ValueOf(b.data).Elem(), a.Parameters()), nil
+}
+
+// e.g. "Filt1_type: Lowpass -> 3" or "Wheel_morph_params.Osc1_shape: 0 -> -20".
+func (change ParamChange) String() string {
+	return fmt.Sprintf("%s: %s -> %s", change.Path, change.valueString(change.Old), change.valueString(change.New))
+}
+
+// helpers
+
+func diffParameters(a reflect.Value, b reflect.Value, parameters []Parameter) []ParamChange {
+	var result []ParamChange
+
+	for _, parameter := range parameters {
+		oldValue, _ := getParameter(a, parameter.Path)
+		newValue, _ := getParameter(b, parameter.Path)
+		if oldValue != newValue {
+			rf, _, _ := lookupParameter(a, parameter.Path)
+			result = append(result, ParamChange{parameter.Path, oldValue, newValue, rf.Type()})
+		}
+	}
+	return result
+}
+
+// Names enum values, writing everything else as a number.
+func (change ParamChange) valueString(value int) string {
+	if change.typ != nil && change.typ.Kind() != reflect.Bool {
+		rv := reflect.New(change.typ).Elem()
+		setNumber(rv, value)
+		if stringer, ok := rv.Interface().(fmt.Stringer); ok {
+			return stringer.String()
+		}
+	}
+	return fmt.Sprintf("%d", value)
+}
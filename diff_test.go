@@ -0,0 +1,51 @@
+package nordlead3
+
+import (
+	"testing"
+)
+
+func TestDiffPrograms(t *testing.T) {
+	a, b := NewInitProgram(), NewInitProgram()
+	if changes, err := DiffPrograms(a, b); err != nil || len(changes) != 0 {
+		t.Errorf("Expected identical programs to have no differences, got %v (%v)", changes, err)
+	}
+
+	b.SetName("Other")
+	b.Set("Filt1_type", int(FilterBandReject))
+	b.Set("Wheel_morph_params.Osc1_shape", -20)
+	b.Set("Chord_positions[3]", 12)
+
+	changes, err := DiffPrograms(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"Chord_positions[3]: 0 -> 12", "Filt1_type: Lowpass -> Band reject", "Wheel_morph_params.Osc1_shape: 0 -> -20"}
+	found := make(map[string]bool)
+	for _, change := range changes {
+		found[change.String()] = true
+	}
+	for _, line := range expected {
+		if !found[line] {
+			t.Errorf("Expected %q among the changes %v", line, changes)
+		}
+	}
+	if len(changes) != len(expected) {
+		t.Errorf("Expected %d changes, got %v", len(expected), changes)
+	}
+
+	if _, err := DiffPrograms(a, nil); err != ErrUninitialized {
+		t.Errorf("Expected ErrUninitialized, got %v", err)
+	}
+}
+
+func TestDiffPerformances(t *testing.T) {
+	a := NewInitPerformance()
+	b := a.clone()
+	b.Set("Patch_data_b.Lfo1_rate", 99)
+	b.Set("Patch_data_d.Velocity_morph_params.Output_level", -5)
+
+	changes, _ := DiffPerformances(a, b)
+	if len(changes) != 2 || changes[0].Path != "Patch_data_b.Lfo1_rate" || changes[0].New != 99 || changes[1].Path != "Patch_data_d.Velocity_morph_params.Output_level" || changes[1].New != -5 {
+		t.Errorf("Unexpected changes %v", changes)
+	}
+}
@@ -0,0 +1,217 @@
+package nordlead3
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+)
+
+// Identifies the sound of a patch. See Program.ContentHash.
+type PatchHash [sha256.Size]byte
+
+// Patches of one type which share a sound, in memory order. Dedupe keeps the first and removes the rest.
+type DuplicateGroup struct {
+	PatchType PatchType
+	Locations []MemoryLocation
+}
+
+// A hash of the performance's data as dumped, including the programs in its slots. See Program.ContentHash.
+func (performance *Performance) ContentHash() PatchHash {
+	if performance == nil {
+		return PatchHash{}
+	}
+	data := *performance.data
+	data.Version_number = 0
+	return hashDump(data.dumpSysex())
+}
+
+// A hash of the program's data as dumped. The name, category and OS version are left out, so copies of a
+// sound have the same hash whatever they are called, wherever they are held and whichever OS saved them,
+// as FindNearDuplicates sees them.
+func (program *Program) ContentHash() PatchHash {
+	if program == nil {
+		return PatchHash{}
+	}
+	data := *program.data
+	data.Version_number = 0
+	return hashDump(data.dumpSysex())
+}
+
+// Removes the duplicates FindNearDuplicates finds with the tolerance, keeping the first patch of each group,
+// then closes the gaps left in each affected bank by moving its later patches up, in order. Moved programs
+// are followed as SetReferenceTracking says, and removed ones to the copy that was kept. Returns the groups
+// found, with their locations as they were before. This is a single operation for Undo.
+func (memory *PatchMemory) Dedupe(tolerance int) []DuplicateGroup {
+	groups := memory.FindNearDuplicates(tolerance)
+
+	memory.record(fmt.Sprintf("dedupe %d groups", len(groups)), func() error {
+		type bankKey struct {
+			pt   PatchType
+			bank int
+		}
+		affected := make(map[bankKey]bool)
+		replacements := make(map[*Program]*Program)
+
+		for _, group := range groups {
+			kept, _ := memory.at(patchRef{group.PatchType, MemoryT, group.Locations[0].index()}).(*Program)
+			for _, ml := range group.Locations[1:] {
+				if program, ok := memory.at(patchRef{group.PatchType, MemoryT, ml.index()}).(*Program); ok {
+					replacements[program] = kept
+				}
+				affected[bankKey{group.PatchType, ml.Bank}] = true
+			}
+		}
+		return memory.followPrograms(replacements, func() error {
+			for _, group := range groups {
+				for _, ml := range group.Locations[1:] {
+					memory.clear(patchRef{group.PatchType, MemoryT, ml.index()})
+				}
+			}
+			for key := range affected {
+				memory.compactBank(key.pt, key.bank)
+			}
+			return nil
+		})
+	})
+	return groups
+}
+
+// Groups the patches of each type, programs first, whose content hashes match. Only groups of two or more
+// are returned, ordered by the location of their first patch.
+func (memory *PatchMemory) FindDuplicates() []DuplicateGroup {
+	var result []DuplicateGroup
+
+	for _, pt := range []PatchType{ProgramT, PerformanceT} {
+		var order []PatchHash
+		byHash := make(map[PatchHash][]MemoryLocation)
+		for _, ref := range memory.initializedRefs(pt) {
+			p, _ := memory.get(ref)
+			hash := p.ContentHash()
+			if len(byHash[hash]) == 0 {
+				order = append(order, hash)
+			}
+			byHash[hash] = append(byHash[hash], MemoryLocation{ref.bank(), ref.location()})
+		}
+		for _, hash := range order {
+			if locations := byHash[hash]; len(locations) > 1 {
+				result = append(result, DuplicateGroup{pt, locations})
+			}
+		}
+	}
+	return result
+}
+
+// As FindDuplicates, but also counts as duplicates patches whose every parameter lies within tolerance
+// steps of the first patch of the group. Switches and named values such as waveforms must match exactly,
+// as neighbouring values sound nothing alike. A tolerance of 0 is the same as FindDuplicates.
+func (memory *PatchMemory) FindNearDuplicates(tolerance int) []DuplicateGroup {
+	if tolerance <= 0 {
+		return memory.FindDuplicates()
+	}
+	var result []DuplicateGroup
+
+	for _, pt := range []PatchType{ProgramT, PerformanceT} {
+		refs := memory.initializedRefs(pt)
+		exact := exactParameters(reflect.New(dataType(pt)).Elem(), 0)
+		values := make([][]int, len(refs))
+		for i, ref := range refs {
+			values[i] = parameterValues(reflect.ValueOf(memory.patchData(ref)).Elem(), 0)
+		}
+
+		grouped := make([]bool, len(refs))
+		for i, first := range refs {
+			if grouped[i] {
+				continue
+			}
+			group := DuplicateGroup{pt, []MemoryLocation{{first.bank(), first.location()}}}
+			for j := i + 1; j < len(refs); j++ {
+				if !grouped[j] && withinTolerance(values[i], values[j], exact, tolerance) {
+					grouped[j] = true
+					group.Locations = append(group.Locations, MemoryLocation{refs[j].bank(), refs[j].location()})
+				}
+			}
+			if len(group.Locations) > 1 {
+				result = append(result, group)
+			}
+		}
+	}
+	return result
+}
+
+// helpers
+
+// Moves the patches of the bank up, in order, so that any blanks are left at its end.
+func (memory *PatchMemory) compactBank(pt PatchType, bank int) {
+	next := 0
+	for i := 0; i < BankSize; i++ {
+		ref := patchRef{pt, MemoryT, index(bank, i)}
+		if p := memory.at(ref); p != nil {
+			if i != next {
+				memory.put(patchRef{pt, MemoryT, index(bank, next)}, p)
+				memory.put(ref, nil)
+			}
+			next++
+		}
+	}
+}
+
+func hashDump(data *[]byte, err error) PatchHash {
+	if err != nil {
+		return PatchHash{}
+	}
+	return sha256.Sum256(*data)
+}
+
+// The data of the patch at ref, as a *ProgramData or *PerformanceData.
+func (memory *PatchMemory) patchData(ref patchRef) interface{} {
+	switch p := memory.at(ref).(type) {
+	case *Performance:
+		return p.data
+	case *Program:
+		return p.data
+	}
+	return nil
+}
+
+// Whether each parameter of rv, in the order listParameters lists them, is a switch or a named value.
+func exactParameters(rv reflect.Value, depth int) []bool {
+	var result []bool
+	rt := rv.Type()
+	stringer := reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		rf := rv.Field(i)
+
+		if sf.Name == "Version_number" || skipField(sf, depth) {
+			continue
+		}
+		switch rf.Kind() {
+		case reflect.Struct:
+			result = append(result, exactParameters(rf, depth+1)...)
+		case reflect.Array:
+			elem := rf.Type().Elem()
+			for n := 0; n < rf.Len(); n++ {
+				result = append(result, elem.Kind() == reflect.Bool || elem.Implements(stringer))
+			}
+		default:
+			result = append(result, rf.Kind() == reflect.Bool || sf.Type.Implements(stringer))
+		}
+	}
+	return result
+}
+
+func withinTolerance(a []int, b []int, exact []bool, tolerance int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if exact[i] && a[i] != b[i] {
+			return false
+		}
+		if a[i]-b[i] > tolerance || b[i]-a[i] > tolerance {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,157 @@
+package nordlead3
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestContentHashIgnoresName(t *testing.T) {
+	memory := populatedMemory(t, "Program-BladeRun     ZON-1.18.syx")
+	original, _ := memory.GetProgram(MemoryLocation{validProgramBank, validProgramLocation})
+
+	renamed := original.clone()
+	renamed.SetName("Another name")
+	renamed.SetCategory(original.Category() + 1)
+	if renamed.ContentHash() != original.ContentHash() {
+		t.Errorf("Expected the name and category to be left out of the hash")
+	}
+
+	value, _ := renamed.Get("Filt_resonance")
+	renamed.Set("Filt_resonance", value^1)
+	if renamed.ContentHash() == original.ContentHash() {
+		t.Errorf("Expected an edit to change the hash")
+	}
+	if NewInitPerformance().ContentHash() == (PatchHash{}) {
+		t.Errorf("Expected performances to be hashed")
+	}
+}
+
+func TestContentHashIgnoresVersion(t *testing.T) {
+	memory := populatedMemory(t, "Program-BladeRun     ZON-1.18.syx")
+	original, _ := memory.GetProgram(MemoryLocation{validProgramBank, validProgramLocation})
+
+	upgraded := original.clone()
+	if err := upgraded.Upgrade(); err != nil {
+		t.Fatal(err)
+	}
+	if upgraded.ContentHash() != original.ContentHash() {
+		t.Errorf("Expected the 1.18 and %.2f copies to have the same hash", upgraded.Version())
+	}
+
+	memory.set(patchRef{ProgramT, MemoryT, index(0, 0)}, upgraded)
+	if groups := memory.FindDuplicates(); len(groups) != 1 {
+		t.Errorf("Expected the copies to be exact duplicates, got %v", groups)
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	memory, blade := duplicatesMemory(t)
+	groups := memory.FindDuplicates()
+
+	expected := []DuplicateGroup{
+		{ProgramT, []MemoryLocation{{0, 0}, {0, 2}, {validProgramBank, validProgramLocation}}},
+		{PerformanceT, []MemoryLocation{{0, 0}, {1, 0}}},
+	}
+	if !reflect.DeepEqual(groups, expected) {
+		t.Errorf("Expected %v, got %v", expected, groups)
+	}
+
+	near := blade.clone()
+	value, _ := near.Get("Filt_resonance")
+	near.Set("Filt_resonance", value^1)
+	memory.set(patchRef{ProgramT, MemoryT, index(5, 0)}, near)
+	if groups := memory.FindNearDuplicates(0); len(groups[0].Locations) != 3 {
+		t.Errorf("Expected an edited copy not to be an exact duplicate, got %v", groups)
+	}
+	if groups := memory.FindNearDuplicates(1); len(groups[0].Locations) != 4 {
+		t.Errorf("Expected an edited copy to be a near duplicate, got %v", groups)
+	}
+
+	waveform, _ := near.Get("Lfo1_waveform")
+	near.Set("Lfo1_waveform", waveform^1)
+	if groups := memory.FindNearDuplicates(1); len(groups[0].Locations) != 3 {
+		t.Errorf("Expected a copy with another waveform not to be a near duplicate, got %v", groups)
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	memory, _ := duplicatesMemory(t)
+	memory.Dedupe(0)
+
+	titles := []string{"Blade", "Other", "", ""}
+	for location, title := range titles {
+		program, _ := memory.GetProgram(MemoryLocation{0, location})
+		if program.Title() != title && !(title == "" && program == nil) {
+			t.Errorf("Expected %q at 0:%03d after compaction, got %v", title, location, program)
+		}
+	}
+	if memory.NumPrograms(true) != 2 || memory.NumPerformances(true) != 1 || len(memory.FindDuplicates()) != 0 {
+		t.Errorf("Expected the duplicates to be removed")
+	}
+
+	if description, _ := memory.Undo(); description != "dedupe 2 groups" || memory.NumPrograms(true) != 4 {
+		t.Errorf("Expected Dedupe to be undone in one step")
+	}
+}
+
+func TestDedupeFollowsPrograms(t *testing.T) {
+	memory, _ := duplicatesMemory(t)
+	memory.SetReferenceTracking(true)
+	moved := NewInitProgram()
+	moved.SetName("Moved")
+	value, _ := moved.Get("Filt_resonance")
+	moved.Set("Filt_resonance", value^1)
+	memory.set(patchRef{ProgramT, MemoryT, index(0, 5)}, moved)
+
+	perf := MemoryLocation{0, 1}
+	blank := MemoryLocation{0, 6}
+	if err := memory.ComposePerformance(MemoryLocation{0, 5}, blank, blank, blank, ComposeOptions{Destination: perf}); err != nil {
+		t.Fatal(err)
+	}
+
+	memory.Dedupe(0)
+	performance, _ := memory.GetPerformance(perf)
+	if ml := performance.data.Slot(0).Location(); ml != (MemoryLocation{0, 2}) {
+		t.Errorf("Expected slot A to follow \"Moved\" to 0:002, got %d:%03d", ml.Bank, ml.Location)
+	}
+}
+
+func TestDedupeFollowsRemovedDuplicates(t *testing.T) {
+	memory, _ := duplicatesMemory(t)
+	memory.SetReferenceTracking(true)
+	moved := NewInitProgram()
+	moved.SetName("Moved")
+	memory.set(patchRef{ProgramT, MemoryT, index(0, 3)}, moved)
+
+	perf := MemoryLocation{0, 1}
+	blank := MemoryLocation{0, 6}
+	if err := memory.ComposePerformance(MemoryLocation{0, 2}, blank, blank, blank, ComposeOptions{Destination: perf}); err != nil {
+		t.Fatal(err)
+	}
+
+	memory.Dedupe(0)
+	performance, _ := memory.GetPerformance(perf)
+	kept, _ := memory.GetProgram(MemoryLocation{0, 0})
+	if ml := performance.data.Slot(0).Location(); ml != (MemoryLocation{0, 0}) || !slotHolds(performance, 0, kept) {
+		t.Errorf("Expected slot A to follow the removed \"Copy\" to the kept 0:000, got %d:%03d", ml.Bank, ml.Location)
+	}
+}
+
+// Blade run at 0:000 as "Blade", 0:002 as "Copy" and its own location, "Other" at 0:001, and two
+// copies of the init performance.
+func duplicatesMemory(t *testing.T) (*PatchMemory, *Program) {
+	memory := populatedMemory(t, "Program-BladeRun     ZON-1.18.syx")
+	blade, _ := memory.GetProgram(MemoryLocation{validProgramBank, validProgramLocation})
+
+	other := NewInitProgram()
+	other.SetName("Other")
+	for location, program := range []*Program{blade.clone(), other, blade.clone()} {
+		memory.set(patchRef{ProgramT, MemoryT, index(0, location)}, program)
+	}
+	memory.SetName(ProgramT, MemoryLocation{0, 0}, "Blade")
+	memory.SetName(ProgramT, MemoryLocation{0, 2}, "Copy")
+
+	memory.set(patchRef{PerformanceT, MemoryT, index(0, 0)}, NewInitPerformance())
+	memory.set(patchRef{PerformanceT, MemoryT, index(1, 0)}, NewInitPerformance())
+	return memory, blade
+}
@@ -0,0 +1,183 @@
+package nordlead3
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Named values for the ProgramData switch fields, as the NL3 manual names them. The reflection codec
+// handles them as the plain uints they are on the wire.
+
+type Waveform uint
+type LfoWaveform uint
+type FilterType uint
+type FilterSlope uint
+type ModDestination uint
+type OscModType uint
+type ArpeggioMode uint
+type MonoAllocation uint
+
+const (
+	WaveformSaw Waveform = iota
+	WaveformPulse
+	WaveformTriangle
+	WaveformSine
+	WaveformNoise
+	WaveformFM
+)
+
+const (
+	LfoTriangle LfoWaveform = iota
+	LfoSaw
+	LfoSquare
+	LfoSampleAndHold
+	LfoSmoothRandom
+	LfoSine
+)
+
+const (
+	FilterLowpass FilterType = iota
+	FilterHighpass
+	FilterBandpass
+	FilterBandReject
+	FilterClassic
+	FilterDistortion
+)
+
+const (
+	FilterSlope12dB FilterSlope = iota
+	FilterSlope18dB
+	FilterSlope24dB
+)
+
+const (
+	DestOsc1Pitch ModDestination = iota
+	DestOsc2Pitch
+	DestOsc12Pitch
+	DestOsc1Shape
+	DestOsc2Shape
+	DestOsc12Shape
+	DestFilterFrequency
+	DestAmplifier
+	DestOscmix
+	DestOscmod
+	DestFilterResonance
+	DestPan
+)
+
+const (
+	OscModRing OscModType = iota
+	OscModAmplitude
+	OscModFMLinear
+	OscModFMExponential
+	OscModPhase
+	OscModDistortion
+)
+
+const (
+	ArpeggioUp ArpeggioMode = iota
+	ArpeggioDown
+	ArpeggioUpDown
+	ArpeggioRandom
+)
+
+const (
+	MonoLast MonoAllocation = iota
+	MonoLow
+	MonoHigh
+)
+
+var waveformNames = []string{"Saw", "Pulse", "Triangle", "Sine", "Noise", "FM"}
+var lfoWaveformNames = []string{"Triangle", "Saw", "Square", "Sample and hold", "Smooth random", "Sine"}
+var filterTypeNames = []string{"Lowpass", "Highpass", "Bandpass", "Band reject", "Classic", "Distortion"}
+var filterSlopeNames = []string{"12dB", "18dB", "24dB"}
+var modDestinationNames = []string{
+	"Osc 1 pitch", "Osc 2 pitch", "Osc 1+2 pitch", "Osc 1 shape", "Osc 2 shape", "Osc 1+2 shape",
+	"Filter frequency", "Amplifier", "Oscmix", "Oscmod", "Filter resonance", "Pan",
+}
+var oscModTypeNames = []string{"Ring", "Amplitude", "FM linear", "FM exponential", "Phase", "Distortion"}
+var arpeggioModeNames = []string{"Up", "Down", "Up/Down", "Random"}
+var monoAllocationNames = []string{"Last", "Low", "High"}
+
+func (w Waveform) String() string       { return enumString(waveformNames, uint(w)) }
+func (w LfoWaveform) String() string    { return enumString(lfoWaveformNames, uint(w)) }
+func (f FilterType) String() string     { return enumString(filterTypeNames, uint(f)) }
+func (f FilterSlope) String() string    { return enumString(filterSlopeNames, uint(f)) }
+func (d ModDestination) String() string { return enumString(modDestinationNames, uint(d)) }
+func (o OscModType) String() string     { return enumString(oscModTypeNames, uint(o)) }
+func (a ArpeggioMode) String() string   { return enumString(arpeggioModeNames, uint(a)) }
+func (m MonoAllocation) String() string { return enumString(monoAllocationNames, uint(m)) }
+
+func (w Waveform) Valid() bool       { return uint(w) < uint(len(waveformNames)) }
+func (w LfoWaveform) Valid() bool    { return uint(w) < uint(len(lfoWaveformNames)) }
+func (f FilterType) Valid() bool     { return uint(f) < uint(len(filterTypeNames)) }
+func (f FilterSlope) Valid() bool    { return uint(f) < uint(len(filterSlopeNames)) }
+func (d ModDestination) Valid() bool { return uint(d) < uint(len(modDestinationNames)) }
+func (o OscModType) Valid() bool     { return uint(o) < uint(len(oscModTypeNames)) }
+func (a ArpeggioMode) Valid() bool   { return uint(a) < uint(len(arpeggioModeNames)) }
+func (m MonoAllocation) Valid() bool { return uint(m) < uint(len(monoAllocationNames)) }
+
+// The Parse functions accept either the name of a value (in any case) or its number.
+
+func ParseWaveform(s string) (Waveform, error) {
+	value, err := parseEnum(waveformNames, s)
+	return Waveform(value), err
+}
+
+func ParseLfoWaveform(s string) (LfoWaveform, error) {
+	value, err := parseEnum(lfoWaveformNames, s)
+	return LfoWaveform(value), err
+}
+
+func ParseFilterType(s string) (FilterType, error) {
+	value, err := parseEnum(filterTypeNames, s)
+	return FilterType(value), err
+}
+
+func ParseFilterSlope(s string) (FilterSlope, error) {
+	value, err := parseEnum(filterSlopeNames, s)
+	return FilterSlope(value), err
+}
+
+func ParseModDestination(s string) (ModDestination, error) {
+	value, err := parseEnum(modDestinationNames, s)
+	return ModDestination(value), err
+}
+
+func ParseOscModType(s string) (OscModType, error) {
+	value, err := parseEnum(oscModTypeNames, s)
+	return OscModType(value), err
+}
+
+func ParseArpeggioMode(s string) (ArpeggioMode, error) {
+	value, err := parseEnum(arpeggioModeNames, s)
+	return ArpeggioMode(value), err
+}
+
+func ParseMonoAllocation(s string) (MonoAllocation, error) {
+	value, err := parseEnum(monoAllocationNames, s)
+	return MonoAllocation(value), err
+}
+
+// helpers
+
+func enumString(names []string, value uint) string {
+	if value >= uint(len(names)) {
+		return fmt.Sprintf("Unknown: %d", value)
+	}
+	return names[value]
+}
+
+func parseEnum(names []string, s string) (uint, error) {
+	s = strings.TrimSpace(s)
+	for value, name := range names {
+		if strings.EqualFold(name, s) {
+			return uint(value), nil
+		}
+	}
+	if value, err := strconv.ParseUint(s, 10, 0); err == nil && value < uint64(len(names)) {
+		return uint(value), nil
+	}
+	return 0, ErrInvalidValue
+}
@@ -0,0 +1,73 @@
+package nordlead3
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseEnums(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected FilterType
+		err      error
+	}{
+		{"Bandpass", FilterBandpass, nil},
+		{"band reject", FilterBandReject, nil},
+		{" 5 ", FilterDistortion, nil},
+		{"6", 0, ErrInvalidValue},
+		{"-1", 0, ErrInvalidValue},
+		{"Notch", 0, ErrInvalidValue},
+	}
+
+	for _, c := range cases {
+		filterType, err := ParseFilterType(c.input)
+		if filterType != c.expected || err != c.err {
+			t.Errorf("ParseFilterType(%q): expected %v (%v), got %v (%v)", c.input, c.expected, c.err, filterType, err)
+		}
+	}
+
+	if destination, err := ParseModDestination(DestOscmod.String()); destination != DestOscmod || err != nil {
+		t.Errorf("ModDestination did not survive a String/Parse round trip, got %v (%v)", destination, err)
+	}
+	if slope, err := ParseFilterSlope("12db"); slope != FilterSlope12dB || err != nil {
+		t.Errorf("Expected a 12dB slope, got %v (%v)", slope, err)
+	}
+	if ArpeggioMode(4).Valid() || ArpeggioMode(4).String() != "Unknown: 4" {
+		t.Errorf("Expected arpeggio mode 4 to be invalid and unknown, got %q", ArpeggioMode(4))
+	}
+}
+
+func TestSetSwitches(t *testing.T) {
+	program := NewInitProgram()
+
+	if err := program.SetOsc2Waveform(WaveformNoise); err != nil || program.data.Osc2_waveform != WaveformNoise {
+		t.Errorf("Could not set osc 2 to noise (%v)", err)
+	}
+	if err := program.SetLfo1Destination(DestPan + 1); err != ErrInvalidValue {
+		t.Errorf("Expected ErrInvalidValue for LFO 1 destination %d, got %v", DestPan+1, err)
+	}
+	if program.data.Lfo1_destination != DestFilterFrequency {
+		t.Errorf("Rejected LFO 1 destination was stored anyway: %v", program.data.Lfo1_destination)
+	}
+
+	var blank *Program
+	if err := blank.SetFilt1Type(FilterLowpass); err != ErrUninitialized {
+		t.Errorf("Expected ErrUninitialized setting a blank program, got %v", err)
+	}
+}
+
+func TestPrintableContentsShowsNames(t *testing.T) {
+	program := NewInitFMProgram()
+	program.SetFilt2Type(FilterHighpass)
+	contents := program.PrintableContents()
+
+	expected := []string{"nordlead3.Waveform): FM\n", "nordlead3.FilterType): Lowpass\n", "nordlead3.FilterSlope): 24dB\n", "nordlead3.FilterType): Highpass\n"}
+	for _, expected := range expected {
+		if !strings.Contains(contents, expected) {
+			t.Errorf("Expected contents to include %q", expected)
+		}
+	}
+	if program.Filter1() != "Lowpass 24dB" {
+		t.Errorf("Expected filter 1 to be described as a 24dB lowpass, got %q", program.Filter1())
+	}
+}
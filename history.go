@@ -0,0 +1,206 @@
+package nordlead3
+
+import (
+	"fmt"
+)
+
+// The number of operations a PatchMemory keeps for undoing unless SetHistoryDepth says otherwise.
+const DefaultHistoryDepth = 100
+
+// An undoable operation: every location it changed, with the patch there before and after. While it is
+// being recorded, changes holds every put in order; once recorded, one change per location.
+type operation struct {
+	description string
+	changes     []change
+}
+
+type change struct {
+	ref    patchRef
+	before patch // nil if the location was blank
+	after  patch // nil if the operation cleared it
+}
+
+type history struct {
+	undo    []operation
+	redo    []operation
+	depth   int        // 0 for DefaultHistoryDepth, negative to keep no history
+	current *operation // being recorded, if any
+}
+
+// Reverts the most recent operation, returning its description (e.g. "delete program 3:004").
+// Changes made directly to a patch returned by GetProgram or GetPerformance are not recorded.
+func (memory *PatchMemory) Undo() (string, error) {
+	h := &memory.history
+	if len(h.undo) == 0 {
+		return "", ErrNothingToUndo
+	}
+	op := h.undo[len(h.undo)-1]
+	h.undo = h.undo[:len(h.undo)-1]
+
+	for i := len(op.changes) - 1; i >= 0; i-- {
+		memory.store(op.changes[i].ref, op.changes[i].before)
+	}
+	h.redo = append(h.redo, op)
+	return op.description, nil
+}
+
+// Repeats the most recently undone operation, returning its description. Any other operation
+// performed since the undo discards what there was to redo.
+func (memory *PatchMemory) Redo() (string, error) {
+	h := &memory.history
+	if len(h.redo) == 0 {
+		return "", ErrNothingToRedo
+	}
+	op := h.redo[len(h.redo)-1]
+	h.redo = h.redo[:len(h.redo)-1]
+
+	for _, change := range op.changes {
+		memory.store(change.ref, change.after)
+	}
+	h.undo = append(h.undo, op)
+	return op.description, nil
+}
+
+// Keeps at most depth operations for undoing, forgetting the oldest first. A depth of 0 keeps none.
+func (memory *PatchMemory) SetHistoryDepth(depth int) {
+	if depth <= 0 {
+		depth = -1
+	}
+	memory.history.depth = depth
+	memory.history.trim()
+}
+
+// helpers
+
+// Runs action as one undoable operation. Calls nested within another operation become part of it.
+// Whatever action changed is recorded, even if it returns an error or panics.
+func (memory *PatchMemory) record(description string, action func() error) error {
+	h := &memory.history
+	if h.current != nil {
+		return action()
+	}
+
+	h.current = &operation{description: description}
+	defer func() {
+		op := h.current
+		h.current = nil
+
+		if changes := op.net(); len(changes) > 0 {
+			op.changes = changes
+			h.undo = append(h.undo, *op)
+			h.redo = nil
+			h.trim()
+		}
+	}()
+	return action()
+}
+
+// Like record, but if action returns an error or panics everything it changed is put back, leaving memory
+// as it was. Nested within another operation, only the changes made by action are put back.
+func (memory *PatchMemory) atomically(description string, action func() error) error {
+	return memory.record(description, func() (err error) {
+		op := memory.history.current
+		mark := len(op.changes)
+		completed := false
+		defer func() {
+			if !completed || err != nil {
+				for i := len(op.changes) - 1; i >= mark; i-- {
+					memory.store(op.changes[i].ref, op.changes[i].before)
+				}
+				op.changes = op.changes[:mark]
+			}
+		}()
+
+		err = action()
+		completed = true
+		return err
+	})
+}
+
+// Stores the patch at ref, or blanks ref if the patch is nil, recording the change in the current
+// operation. The patch must be of ref's type.
+func (memory *PatchMemory) put(ref patchRef, p patch) {
+	if op := memory.history.current; op != nil {
+		op.changes = append(op.changes, change{ref, memory.at(ref), p})
+	}
+	memory.store(ref, p)
+}
+
+// As put, without recording anything.
+func (memory *PatchMemory) store(ref patchRef, p patch) {
+	switch ref.patchType {
+	case PerformanceT:
+		performance, _ := p.(*Performance)
+		*memory.perfPtr(ref) = performance
+	case ProgramT:
+		program, _ := p.(*Program)
+		*memory.progPtr(ref) = program
+	}
+}
+
+// Returns the patch at ref, or nil if it is blank or invalid.
+func (memory *PatchMemory) at(ref patchRef) patch {
+	p, _ := memory.get(ref)
+	return p
+}
+
+// Replaces the patch at ref with a modified copy, so that the original can be restored by Undo.
+func (memory *PatchMemory) modify(ref patchRef, description string, modification func(p patch) error) error {
+	original, err := memory.get(ref)
+	if err != nil {
+		return err
+	}
+	modified := clonePatch(original)
+	if err := modification(modified); err != nil {
+		return err
+	}
+	return memory.record(describe(description, ref), func() error {
+		memory.put(ref, modified)
+		return nil
+	})
+}
+
+// Condenses the changes to one per location, from its first before to its last after, leaving out
+// locations which ended up as they started.
+func (op *operation) net() []change {
+	var result []change
+	seen := make(map[patchRef]int)
+
+	for _, change := range op.changes {
+		if i, ok := seen[change.ref]; ok {
+			result[i].after = change.after
+		} else {
+			seen[change.ref] = len(result)
+			result = append(result, change)
+		}
+	}
+
+	changed := result[:0]
+	for _, change := range result {
+		if change.before != change.after {
+			changed = append(changed, change)
+		}
+	}
+	return changed
+}
+
+// Describes an operation on the patch at ref, e.g. "delete program 3:004".
+func describe(verb string, ref patchRef) string {
+	return fmt.Sprintf("%s %s %s", verb, ref.patchType, ref.patchLocation())
+}
+
+func (h *history) trim() {
+	depth := h.depth
+	if depth == 0 {
+		depth = DefaultHistoryDepth
+	}
+	if depth < 0 {
+		depth = 0
+	}
+	if len(h.undo) > depth {
+		h.undo = append([]operation(nil), h.undo[len(h.undo)-depth:]...)
+	}
+	if len(h.redo) > depth {
+		h.redo = append([]operation(nil), h.redo[len(h.redo)-depth:]...)
+	}
+}
@@ -0,0 +1,125 @@
+package nordlead3
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestUndoRedo(t *testing.T) {
+	memory := populatedMemory(t, "Program-BladeRun     ZON-1.18.syx")
+	at := MemoryLocation{validProgramBank, validProgramLocation}
+	original, _ := memory.GetProgram(at)
+
+	steps := []struct {
+		description string
+		action      func() error
+	}{
+		{"rename program 3:004", func() error { return memory.SetName(ProgramT, at, "Renamed") }},
+		{"set category of program 3:004", func() error { return memory.SetCategory(ProgramT, at, 3) }},
+		{"swap program 3:004 with 0:000", func() error { return memory.SwapPrograms(at, MemoryLocation{0, 0}) }},
+		{"move 1 to program 1:000", func() error {
+			return memory.MovePrograms([]MemoryLocation{{0, 0}}, MemoryLocation{1, 0}, ConflictAbort)
+		}},
+		{"delete program 1:000", func() error { memory.DeleteProgram(MemoryLocation{1, 0}); return nil }},
+	}
+	for _, step := range steps {
+		if err := step.action(); err != nil {
+			t.Fatalf("%s: %v", step.description, err)
+		}
+	}
+	if memory.NumPrograms(true) != 0 {
+		t.Fatalf("Expected the program to be deleted")
+	}
+
+	for i := len(steps) - 1; i >= 0; i-- {
+		if description, err := memory.Undo(); err != nil || description != steps[i].description {
+			t.Errorf("Expected to undo %q, got %q (%v)", steps[i].description, description, err)
+		}
+	}
+	if program, _ := memory.GetProgram(at); program != original || program.PrintableName() != validProgramName || memory.NumPrograms(true) != 1 {
+		t.Errorf("Undoing the steps did not restore the original program, got %v", program)
+	}
+	if description, _ := memory.Undo(); description != "import" || memory.NumPrograms(true) != 0 {
+		t.Errorf("Expected to undo loading the program, undid %q", description)
+	}
+	if _, err := memory.Undo(); err != ErrNothingToUndo {
+		t.Errorf("Expected ErrNothingToUndo, got %v", err)
+	}
+
+	for range steps[:4] { // the import, rename, category and swap
+		memory.Redo()
+	}
+	program, _ := memory.GetProgram(MemoryLocation{0, 0})
+	if program == nil || program.Title() != "Renamed" || program.category != 3 || memory.NumPrograms(true) != 1 {
+		t.Errorf("Redoing the rename, category and swap gave %v", program)
+	}
+}
+
+func TestUndoImport(t *testing.T) {
+	memory := new(PatchMemory)
+	stream := append(validProgramSysex(t), validPerformanceSysex(t)...)
+
+	memory.Import(bytes.NewReader(stream), ConflictSkip)
+	memory.Import(bytes.NewReader(stream), ConflictSkip) // changes nothing, so is not recorded
+	if _, err := memory.Undo(); err != nil || memory.NumPrograms(true) != 0 || memory.NumPerformances(true) != 0 {
+		t.Errorf("Expected a single undo to remove the whole import (%v)", err)
+	}
+	if _, err := memory.Undo(); err != ErrNothingToUndo {
+		t.Errorf("Expected the second import not to be recorded, got %v", err)
+	}
+}
+
+func TestRedoIsDiscardedByNewOperations(t *testing.T) {
+	memory := populatedMemory(t, "Program-BladeRun     ZON-1.18.syx")
+	at := MemoryLocation{validProgramBank, validProgramLocation}
+
+	memory.SetName(ProgramT, at, "First")
+	memory.Undo()
+	memory.SetName(ProgramT, at, "Second")
+	if _, err := memory.Redo(); err != ErrNothingToRedo {
+		t.Errorf("Expected ErrNothingToRedo, got %v", err)
+	}
+}
+
+func TestFailedOperationsLeaveNoHistory(t *testing.T) {
+	memory := populatedMemory(t, "Program-BladeRun     ZON-1.18.syx")
+	at := MemoryLocation{validProgramBank, validProgramLocation}
+
+	memory.CopyProgramToSlot(at, 0)
+	memory.CopySlotToProgram(0, MemoryLocation{0, 1})
+	err := memory.MovePrograms([]MemoryLocation{at}, MemoryLocation{0, 1}, ConflictAbort)
+	if err != ErrMemoryOccupied {
+		t.Fatalf("Expected the move to fail, got %v", err)
+	}
+	if err := memory.SetName(ProgramT, at, "Far too long for a name"); err != ErrInvalidName {
+		t.Fatalf("Expected ErrInvalidName, got %v", err)
+	}
+
+	if description, _ := memory.Undo(); description != "copy program slot 0 to 0:001" {
+		t.Errorf("Expected the failed operations not to be recorded, undid %q", description)
+	}
+}
+
+func TestHistoryDepth(t *testing.T) {
+	memory := populatedMemory(t, "Program-BladeRun     ZON-1.18.syx")
+	at := MemoryLocation{validProgramBank, validProgramLocation}
+
+	memory.SetHistoryDepth(2)
+	for _, name := range []string{"One", "Two", "Three"} {
+		memory.SetName(ProgramT, at, name)
+	}
+	memory.Undo()
+	memory.Undo()
+	if _, err := memory.Undo(); err != ErrNothingToUndo {
+		t.Errorf("Expected only 2 operations to be kept, got %v", err)
+	}
+	if program, _ := memory.GetProgram(at); program.Title() != "One" {
+		t.Errorf("Expected the oldest rename to be kept, got %q", program.Title())
+	}
+
+	memory.SetHistoryDepth(0)
+	memory.SetName(ProgramT, at, "Four")
+	if _, err := memory.Undo(); err != ErrNothingToUndo {
+		t.Errorf("Expected no history at depth 0, got %v", err)
+	}
+}
@@ -0,0 +1,413 @@
+package nordlead3
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// JSON documents carry every field of the patch data, so that they convert back to identical sysex.
+// Names and enums are written as text; bytes are kept one to one, with trailing NULs dropped.
+
+type programJSON struct {
+	Name     string       `json:"name"`
+	Category string       `json:"category"`
+	Version  float64      `json:"version"`
+	Data     *ProgramData `json:"data"`
+}
+
+type performanceJSON struct {
+	Name     string           `json:"name"`
+	Category string           `json:"category"`
+	Version  float64          `json:"version"`
+	Data     *PerformanceData `json:"data"`
+}
+
+type memoryJSON struct {
+	Performances    []performanceLocationJSON `json:"performances,omitempty"`
+	Programs        []programLocationJSON     `json:"programs,omitempty"`
+	SlotPerformance *Performance              `json:"slot_performance,omitempty"`
+	SlotPrograms    []programSlotJSON         `json:"slot_programs,omitempty"`
+}
+
+type performanceLocationJSON struct {
+	Bank        int          `json:"bank"`
+	Location    int          `json:"location"`
+	Performance *Performance `json:"performance"`
+}
+
+type programLocationJSON struct {
+	Bank     int      `json:"bank"`
+	Location int      `json:"location"`
+	Program  *Program `json:"program"`
+}
+
+type programSlotJSON struct {
+	Slot    int      `json:"slot"`
+	Program *Program `json:"program"`
+}
+
+type locatedPatch struct {
+	ref   patchRef
+	patch patch
+}
+
+// Writes every patch in memory, along with its location, as an indented JSON document.
+func (memory *PatchMemory) ExportJSON(writer io.Writer) error {
+	refs := append(memory.initializedRefs(PerformanceT), memory.initializedRefs(ProgramT)...)
+	return memory.exportJSON(refs, true, writer)
+}
+
+func (memory *PatchMemory) ExportPerformanceBankJSON(bank int, writer io.Writer) error {
+	refs, err := bankRefs(PerformanceT, bank)
+	if err != nil {
+		return err
+	}
+	return memory.exportJSON(refs, false, writer)
+}
+
+func (memory *PatchMemory) ExportProgramBankJSON(bank int, writer io.Writer) error {
+	refs, err := bankRefs(ProgramT, bank)
+	if err != nil {
+		return err
+	}
+	return memory.exportJSON(refs, false, writer)
+}
+
+// The JSON counterpart of Import: loads the patches into the locations given in the document. Offsets
+// in the report are the positions of the patches in the document.
+func (memory *PatchMemory) ImportJSON(input io.Reader, policy ConflictPolicy) (*ImportReport, error) {
+	report := new(ImportReport)
+	var document memoryJSON
+	if err := json.NewDecoder(input).Decode(&document); err != nil {
+		return report, err
+	}
+
+	err := memory.record("import JSON", func() error {
+		for i, entry := range document.patches() {
+			dest, replaced, err := memory.importPatch(entry.patch, entry.ref, policy)
+			report.add(ImportEntry{
+				Offset:    int64(i),
+				Name:      entry.patch.PrintableName(),
+				PatchType: entry.patch.PatchType(),
+				Source:    entry.ref.patchLocation(),
+			}, dest, replaced, err)
+			if err == ErrConflictAborted {
+				return err
+			}
+		}
+		return nil
+	})
+	return report, err
+}
+
+func (memory *PatchMemory) MarshalJSON() ([]byte, error) {
+	refs := append(memory.initializedRefs(PerformanceT), memory.initializedRefs(ProgramT)...)
+	return json.Marshal(memory.jsonDocument(refs, true))
+}
+
+// Replaces the contents of memory with the document. The range policy, history depth and reference
+// tracking are kept, but the history itself is forgotten.
+func (memory *PatchMemory) UnmarshalJSON(data []byte) error {
+	var document memoryJSON
+	if err := json.Unmarshal(data, &document); err != nil {
+		return err
+	}
+
+	*memory = PatchMemory{rangePolicy: memory.rangePolicy, history: history{depth: memory.history.depth}, trackReferences: memory.trackReferences}
+	for _, entry := range document.patches() {
+		if !entry.ref.valid() {
+			return ErrInvalidLocation
+		}
+		memory.set(entry.ref, entry.patch)
+	}
+	return nil
+}
+
+func (performance *Performance) MarshalJSON() ([]byte, error) {
+	return json.Marshal(performanceJSON{
+		Name:     nameToString(performance.name),
+		Category: categoryToString(performance.category),
+		Version:  performance.version,
+		Data:     performance.data,
+	})
+}
+
+func (performance *Performance) UnmarshalJSON(data []byte) error {
+	var document performanceJSON
+	if err := json.Unmarshal(data, &document); err != nil {
+		return err
+	}
+	if document.Data == nil {
+		return ErrUninitialized
+	}
+	name, err := nameFromString(document.Name)
+	if err != nil {
+		return err
+	}
+	category, err := categoryFromString(document.Category)
+	if err != nil {
+		return err
+	}
+
+	*performance = Performance{name: name, category: category, version: document.Version, data: document.Data}
+	return nil
+}
+
+func (program *Program) MarshalJSON() ([]byte, error) {
+	return json.Marshal(programJSON{
+		Name:     nameToString(program.name),
+		Category: categoryToString(program.category),
+		Version:  program.version,
+		Data:     program.data,
+	})
+}
+
+func (program *Program) UnmarshalJSON(data []byte) error {
+	var document programJSON
+	if err := json.Unmarshal(data, &document); err != nil {
+		return err
+	}
+	if document.Data == nil {
+		return ErrUninitialized
+	}
+	name, err := nameFromString(document.Name)
+	if err != nil {
+		return err
+	}
+	category, err := categoryFromString(document.Category)
+	if err != nil {
+		return err
+	}
+
+	*program = Program{name: name, category: category, version: document.Version, data: document.Data}
+	return nil
+}
+
+func (name PatchName) MarshalText() ([]byte, error) {
+	return []byte(nameToString([16]byte(name))), nil
+}
+
+func (name *PatchName) UnmarshalText(text []byte) error {
+	parsed, err := nameFromString(string(text))
+	if err == nil {
+		*name = PatchName(parsed)
+	}
+	return err
+}
+
+// Enums are written by name. Values the unit does not define are written as numbers so that they round-trip.
+
+func (w Waveform) MarshalText() ([]byte, error) {
+	return marshalEnum(waveformNames, uint(w))
+}
+
+func (w LfoWaveform) MarshalText() ([]byte, error) {
+	return marshalEnum(lfoWaveformNames, uint(w))
+}
+
+func (f FilterType) MarshalText() ([]byte, error) {
+	return marshalEnum(filterTypeNames, uint(f))
+}
+
+func (f FilterSlope) MarshalText() ([]byte, error) {
+	return marshalEnum(filterSlopeNames, uint(f))
+}
+
+func (d ModDestination) MarshalText() ([]byte, error) {
+	return marshalEnum(modDestinationNames, uint(d))
+}
+
+func (o OscModType) MarshalText() ([]byte, error) {
+	return marshalEnum(oscModTypeNames, uint(o))
+}
+
+func (a ArpeggioMode) MarshalText() ([]byte, error) {
+	return marshalEnum(arpeggioModeNames, uint(a))
+}
+
+func (m MonoAllocation) MarshalText() ([]byte, error) {
+	return marshalEnum(monoAllocationNames, uint(m))
+}
+
+func (w *Waveform) UnmarshalText(text []byte) error {
+	return unmarshalEnum(waveformNames, text, func(value uint) { *w = Waveform(value) })
+}
+
+func (w *LfoWaveform) UnmarshalText(text []byte) error {
+	return unmarshalEnum(lfoWaveformNames, text, func(value uint) { *w = LfoWaveform(value) })
+}
+
+func (f *FilterType) UnmarshalText(text []byte) error {
+	return unmarshalEnum(filterTypeNames, text, func(value uint) { *f = FilterType(value) })
+}
+
+func (f *FilterSlope) UnmarshalText(text []byte) error {
+	return unmarshalEnum(filterSlopeNames, text, func(value uint) { *f = FilterSlope(value) })
+}
+
+func (d *ModDestination) UnmarshalText(text []byte) error {
+	return unmarshalEnum(modDestinationNames, text, func(value uint) { *d = ModDestination(value) })
+}
+
+func (o *OscModType) UnmarshalText(text []byte) error {
+	return unmarshalEnum(oscModTypeNames, text, func(value uint) { *o = OscModType(value) })
+}
+
+func (a *ArpeggioMode) UnmarshalText(text []byte) error {
+	return unmarshalEnum(arpeggioModeNames, text, func(value uint) { *a = ArpeggioMode(value) })
+}
+
+func (m *MonoAllocation) UnmarshalText(text []byte) error {
+	return unmarshalEnum(monoAllocationNames, text, func(value uint) { *m = MonoAllocation(value) })
+}
+
+// helpers
+
+func (memory *PatchMemory) exportJSON(refs []patchRef, withSlots bool, writer io.Writer) error {
+	document := memory.jsonDocument(refs, withSlots)
+	if len(document.patches()) == 0 {
+		return ErrNoDataToWrite
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
+
+// Collects the patches at the given locations, skipping blank ones, and optionally the slot contents.
+func (memory *PatchMemory) jsonDocument(refs []patchRef, withSlots bool) memoryJSON {
+	var document memoryJSON
+
+	for _, ref := range refs {
+		if patch, err := memory.get(ref); err == nil {
+			document.add(ref, patch)
+		}
+	}
+	if withSlots {
+		document.SlotPerformance = memory.slotPerformance
+		for slot, program := range memory.slotPrograms {
+			if program != nil {
+				document.SlotPrograms = append(document.SlotPrograms, programSlotJSON{slot, program})
+			}
+		}
+	}
+	return document
+}
+
+func (memory *PatchMemory) importPatch(patch patch, dest patchRef, policy ConflictPolicy) (patchRef, bool, error) {
+	if !dest.valid() {
+		return dest, false, ErrInvalidLocation
+	}
+
+	var err error
+	switch patch := patch.(type) {
+	case *Performance:
+		err = memory.enforceRanges(patch.data.Validate, patch.data.clamp)
+	case *Program:
+		err = memory.enforceRanges(patch.data.Validate, patch.data.clamp)
+	}
+	if err != nil {
+		return dest, false, err
+	}
+	return memory.setResolved(patch, dest, policy)
+}
+
+func (document *memoryJSON) add(ref patchRef, patch patch) {
+	switch patch := patch.(type) {
+	case *Performance:
+		document.Performances = append(document.Performances, performanceLocationJSON{ref.bank(), ref.location(), patch})
+	case *Program:
+		document.Programs = append(document.Programs, programLocationJSON{ref.bank(), ref.location(), patch})
+	}
+}
+
+// Returns the patches in the document, in document order, with the location each belongs in.
+// Entries without a patch are left out.
+func (document *memoryJSON) patches() []locatedPatch {
+	var result []locatedPatch
+
+	for _, entry := range document.Performances {
+		if entry.Performance != nil {
+			result = append(result, locatedPatch{patchRef{PerformanceT, MemoryT, index(entry.Bank, entry.Location)}, entry.Performance})
+		}
+	}
+	for _, entry := range document.Programs {
+		if entry.Program != nil {
+			result = append(result, locatedPatch{patchRef{ProgramT, MemoryT, index(entry.Bank, entry.Location)}, entry.Program})
+		}
+	}
+	if document.SlotPerformance != nil {
+		result = append(result, locatedPatch{performanceSlotRef, document.SlotPerformance})
+	}
+	for _, entry := range document.SlotPrograms {
+		if entry.Program != nil {
+			result = append(result, locatedPatch{patchRef{ProgramT, SlotT, entry.Slot}, entry.Program})
+		}
+	}
+	return result
+}
+
+func categoryToString(category uint8) string {
+	if category < uint8(len(Categories)) {
+		return Categories[category]
+	}
+	return strconv.Itoa(int(category))
+}
+
+func categoryFromString(s string) (uint8, error) {
+	for category, name := range Categories {
+		if strings.EqualFold(name, s) {
+			return uint8(category), nil
+		}
+	}
+	category, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return 0, ErrInvalidCategory
+	}
+	return uint8(category), nil
+}
+
+// Each byte of the name becomes one character, so that no byte value is lost.
+func nameToString(name [16]byte) string {
+	var result []rune
+	for _, char := range name {
+		result = append(result, rune(char))
+	}
+	return strings.TrimRight(string(result), "\x00")
+}
+
+func nameFromString(s string) ([16]byte, error) {
+	var name [16]byte
+	i := 0
+
+	for _, char := range s {
+		if i >= len(name) || char > 0xFF {
+			return name, ErrInvalidName
+		}
+		name[i] = byte(char)
+		i++
+	}
+	return name, nil
+}
+
+func marshalEnum(names []string, value uint) ([]byte, error) {
+	if value < uint(len(names)) {
+		return []byte(names[value]), nil
+	}
+	return []byte(strconv.FormatUint(uint64(value), 10)), nil
+}
+
+func unmarshalEnum(names []string, text []byte, set func(uint)) error {
+	value, err := parseEnum(names, string(text))
+	if err != nil {
+		number, numberErr := strconv.ParseUint(string(text), 10, 0)
+		if numberErr != nil {
+			return err
+		}
+		value = uint(number)
+	}
+	set(value)
+	return nil
+}
@@ -0,0 +1,99 @@
+package nordlead3
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	memory := populatedMemory(t, "AllPrograms.syx")
+	helperLoadFromFile(t, memory, "AllPerformances.syx")
+	helperLoadFromFile(t, memory, "PerfSlot.syx")
+	helperLoadFromFile(t, memory, "Program-Elektro         -1.20.syx")
+
+	var document bytes.Buffer
+	if err := memory.ExportJSON(&document); err != nil {
+		t.Fatal(err)
+	}
+	reloaded := new(PatchMemory)
+	report, err := reloaded.ImportJSON(&document, ConflictSkip)
+	if err != nil || report.NumRejected() != 0 {
+		t.Fatalf("Expected a clean import, got %s (%v)", report, err)
+	}
+
+	exports := []func(*PatchMemory, *bytes.Buffer) error{
+		func(m *PatchMemory, b *bytes.Buffer) error { return m.ExportAllPrograms(b) },
+		func(m *PatchMemory, b *bytes.Buffer) error { return m.ExportAllPerformances(b) },
+		func(m *PatchMemory, b *bytes.Buffer) error { return m.ExportPerformanceSlot(b) },
+		func(m *PatchMemory, b *bytes.Buffer) error { return m.ExportProgramSlot(2, b) },
+	}
+	for _, export := range exports {
+		var expected, received bytes.Buffer
+		if err := export(memory, &expected); err != nil {
+			t.Fatal(err)
+		}
+		if err := export(reloaded, &received); err != nil {
+			t.Fatal(err)
+		}
+		expectedBytes, receivedBytes := expected.Bytes(), received.Bytes()
+		binaryExpectEqual(t, &expectedBytes, &receivedBytes)
+	}
+}
+
+func TestProgramJSONIsReadable(t *testing.T) {
+	memory := populatedMemory(t, "Program-BladeRun     ZON-1.18.syx")
+	program, _ := memory.GetProgram(MemoryLocation{validProgramBank, validProgramLocation})
+
+	document, err := json.Marshal(program)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{`"name":"Blade run    ZON"`, `"category":"Pad"`, `"version":1.18`, `"Filt1_type":"Band reject"`, `"Filt1_slope":"24dB"`, `"Mono_allocation_mode":"Last"`, `"Osc1_shape":`} {
+		if !strings.Contains(string(document), expected) {
+			t.Errorf("Expected %s in %s", expected, document)
+		}
+	}
+}
+
+func TestJSONKeepsUndefinedValues(t *testing.T) {
+	program := NewInitProgram()
+	program.data.Osc2_waveform = 7
+	program.data.Arp_mask = 0xABCD
+
+	document, err := json.Marshal(program)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded := new(Program)
+	if err := json.Unmarshal(document, reloaded); err != nil {
+		t.Fatal(err)
+	}
+	if *reloaded.data != *program.data || reloaded.name != program.name {
+		t.Errorf("Program did not survive JSON: %s", document)
+	}
+}
+
+func TestImportJSONRespectsOccupiedLocations(t *testing.T) {
+	memory := populatedMemory(t, "PerfBank1.syx")
+	var document bytes.Buffer
+	if err := memory.ExportPerformanceBankJSON(0, &document); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := memory.ImportJSON(bytes.NewReader(document.Bytes()), ConflictSkip)
+	if err != nil || report.NumImported() != 0 || report.NumRejected() != memory.NumPerformances(true) {
+		t.Errorf("Expected every performance to be rejected, got %s (%v)", report, err)
+	}
+	report, _ = memory.ImportJSON(bytes.NewReader(document.Bytes()), ConflictOverwrite)
+	for _, entry := range report.Entries {
+		if entry.Outcome != OutcomeOverwritten || entry.PatchType != PerformanceT || entry.Source != entry.Destination {
+			t.Errorf("Expected every performance to be overwritten in place, got %s", report)
+			break
+		}
+	}
+	if len(report.Entries) != memory.NumPerformances(true) {
+		t.Errorf("Expected an entry per performance, got %d", len(report.Entries))
+	}
+}
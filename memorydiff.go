@@ -0,0 +1,211 @@
+package nordlead3
+
+import (
+	"fmt"
+	"sort"
+)
+
+// changeKinds: how a patch differs from one dump to the next
+const (
+	ChangeAdded   ChangeKind = iota // a new patch at To
+	ChangeRemoved                   // the patch at From is gone
+	ChangeRenamed                   // the patch at From (and To) has a new name or category but the same sound
+	ChangeMoved                     // the sound at From is now at To, perhaps renamed
+	ChangeEdited                    // the sound at From (and To) has changed
+)
+
+type ChangeKind int
+
+// One difference between two memories. Old and New are the patch's names before and after, blank where
+// the patch did not exist.
+type MemoryChange struct {
+	Kind      ChangeKind
+	PatchType PatchType
+	From      MemoryLocation
+	To        MemoryLocation
+	Old       string
+	New       string
+}
+
+// A location which both sides of a merge changed, each in its own way. Base, Ours and Theirs are the
+// names of the patch there in each memory, blank where it was empty.
+type MergeConflict struct {
+	PatchType PatchType
+	Location  MemoryLocation
+	Base      string
+	Ours      string
+	Theirs    string
+}
+
+var changeKindNames = []string{"added", "removed", "renamed", "moved", "edited"}
+
+// Lists what changed from a to b, location by location, programs first. A sound found at a new
+// location, even under a new name, is reported as moved rather than as removed and added. Slots are
+// not compared.
+func DiffMemory(a *PatchMemory, b *PatchMemory) []MemoryChange {
+	var result []MemoryChange
+	for _, pt := range []PatchType{ProgramT, PerformanceT} {
+		result = append(result, diffMemory(a, b, pt)...)
+	}
+	return result
+}
+
+// Combines the changes made from base to ours and from base to theirs into a new memory. Where both sides
+// changed a location differently, the merged memory keeps ours and the location is listed as a conflict.
+// Merging is done location by location, so a patch moved on one side and edited on the other conflicts.
+// Slots and the range policy are taken from ours.
+func MergeMemory(base *PatchMemory, ours *PatchMemory, theirs *PatchMemory) (*PatchMemory, []MergeConflict) {
+	merged := &PatchMemory{rangePolicy: ours.rangePolicy}
+	var conflicts []MergeConflict
+
+	for _, pt := range []PatchType{ProgramT, PerformanceT} {
+		for i := 0; valid(pt, MemoryT, i); i++ {
+			ref := patchRef{pt, MemoryT, i}
+			b, o, t := base.at(ref), ours.at(ref), theirs.at(ref)
+
+			result := o
+			switch {
+			case samePatch(o, t), samePatch(o, b):
+				result = t
+			case samePatch(t, b):
+			default:
+				conflicts = append(conflicts, MergeConflict{pt, MemoryLocation{ref.bank(), ref.location()}, nameOf(b), nameOf(o), nameOf(t)})
+			}
+			if result != nil {
+				merged.store(ref, clonePatch(result))
+			}
+		}
+	}
+
+	for slot := 0; slot < len(ours.slotPrograms); slot++ {
+		ref := patchRef{ProgramT, SlotT, slot}
+		if p := ours.at(ref); p != nil {
+			merged.store(ref, clonePatch(p))
+		}
+	}
+	if p := ours.at(performanceSlotRef); p != nil {
+		merged.store(performanceSlotRef, clonePatch(p))
+	}
+	return merged, conflicts
+}
+
+func (kind ChangeKind) String() string {
+	return enumString(changeKindNames, uint(kind))
+}
+
+// e.g. `program 0:005 -> 1:000 moved "Blade run    ZON"`.
+func (change MemoryChange) String() string {
+	from, to := fmt.Sprintf("%d:%03d", change.From.Bank, change.From.Location), fmt.Sprintf("%d:%03d", change.To.Bank, change.To.Location)
+	switch change.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("%-11s %s %s %q", change.PatchType, to, change.Kind, change.New)
+	case ChangeRemoved:
+		return fmt.Sprintf("%-11s %s %s %q", change.PatchType, from, change.Kind, change.Old)
+	case ChangeMoved:
+		if change.Old != change.New {
+			return fmt.Sprintf("%-11s %s -> %s %s %q -> %q", change.PatchType, from, to, change.Kind, change.Old, change.New)
+		}
+		return fmt.Sprintf("%-11s %s -> %s %s %q", change.PatchType, from, to, change.Kind, change.New)
+	default:
+		if change.Old != change.New {
+			return fmt.Sprintf("%-11s %s %s %q -> %q", change.PatchType, to, change.Kind, change.Old, change.New)
+		}
+		return fmt.Sprintf("%-11s %s %s %q", change.PatchType, to, change.Kind, change.New)
+	}
+}
+
+// helpers
+
+func diffMemory(a *PatchMemory, b *PatchMemory, pt PatchType) []MemoryChange {
+	var result []MemoryChange
+	var vacated []int // locations whose sound in a is not at the same place in b
+	filled := make(map[PatchHash][]int)
+	placed := make(map[int]bool) // locations in b which received a moved sound
+	moved := make(map[int]bool)  // locations in a whose sound was moved
+	hashA, hashB := a.contentHashes(pt), b.contentHashes(pt)
+
+	for i := 0; valid(pt, MemoryT, i); i++ {
+		ref := patchRef{pt, MemoryT, i}
+		pa, pb := a.at(ref), b.at(ref)
+		if pa != nil && pb != nil && hashA[i] == hashB[i] {
+			if !samePatch(pa, pb) {
+				result = append(result, memoryChange(ChangeRenamed, ref, ref, pa, pb))
+			}
+			continue
+		}
+		if pa != nil {
+			vacated = append(vacated, i)
+		}
+		if pb != nil {
+			filled[hashB[i]] = append(filled[hashB[i]], i)
+		}
+	}
+
+	// Match each vacated sound with the first location it turns up at in b
+	for _, i := range vacated {
+		if targets := filled[hashA[i]]; len(targets) > 0 {
+			from, to := patchRef{pt, MemoryT, i}, patchRef{pt, MemoryT, targets[0]}
+			filled[hashA[i]] = targets[1:]
+			result = append(result, memoryChange(ChangeMoved, from, to, a.at(from), b.at(to)))
+			placed[to.index], moved[i] = true, true
+		}
+	}
+
+	for _, i := range vacated {
+		if ref := (patchRef{pt, MemoryT, i}); !moved[i] {
+			if b.initialized(ref) && !placed[i] {
+				result = append(result, memoryChange(ChangeEdited, ref, ref, a.at(ref), b.at(ref)))
+			} else {
+				result = append(result, memoryChange(ChangeRemoved, ref, ref, a.at(ref), nil))
+			}
+		}
+	}
+	for _, targets := range filled {
+		for _, i := range targets {
+			if ref := (patchRef{pt, MemoryT, i}); !a.initialized(ref) || moved[i] {
+				result = append(result, memoryChange(ChangeAdded, ref, ref, nil, b.at(ref)))
+			}
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].sortIndex() < result[j].sortIndex()
+	})
+	return result
+}
+
+func memoryChange(kind ChangeKind, from patchRef, to patchRef, old patch, new patch) MemoryChange {
+	return MemoryChange{kind, from.patchType, MemoryLocation{from.bank(), from.location()}, MemoryLocation{to.bank(), to.location()}, nameOf(old), nameOf(new)}
+}
+
+// Changes are listed by the location they leave a patch at, or for removals, the location they empty.
+func (change MemoryChange) sortIndex() int {
+	if change.Kind == ChangeRemoved {
+		return change.From.index()
+	}
+	return change.To.index()
+}
+
+// The ContentHash of each location of memory, zero where it is blank.
+func (memory *PatchMemory) contentHashes(pt PatchType) map[int]PatchHash {
+	result := make(map[int]PatchHash)
+	for _, ref := range memory.initializedRefs(pt) {
+		result[ref.index] = memory.at(ref).ContentHash()
+	}
+	return result
+}
+
+// Whether a and b are the same sound under the same name and category, or are both blank.
+func samePatch(a patch, b patch) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.PrintableName() == b.PrintableName() && a.PrintableCategory() == b.PrintableCategory() && a.ContentHash() == b.ContentHash()
+}
+
+func nameOf(p patch) string {
+	if p == nil {
+		return ""
+	}
+	return p.PrintableName()
+}
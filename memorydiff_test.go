@@ -0,0 +1,93 @@
+package nordlead3
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDiffMemory(t *testing.T) {
+	a := populatedMemory(t, "AllPrograms.syx")
+	b := populatedMemory(t, "AllPrograms.syx")
+	if changes := DiffMemory(a, b); len(changes) != 0 {
+		t.Fatalf("Expected identical dumps to have no differences, got %v", changes)
+	}
+
+	refs := a.initializedRefs(ProgramT)
+	blank, _ := b.nextFree(refs[0], false)
+	location := func(ref patchRef) MemoryLocation { return MemoryLocation{ref.bank(), ref.location()} }
+
+	b.MovePrograms([]MemoryLocation{location(refs[0])}, location(blank), ConflictAbort)
+	b.SetName(ProgramT, location(refs[1]), "Renamed")
+	b.modify(refs[2], "edit", func(p patch) error {
+		value, _ := p.(*Program).Get("Filt_resonance")
+		return p.(*Program).Set("Filt_resonance", value^1)
+	})
+	b.DeleteProgram(location(refs[3]))
+	b.ImportTo(bytes.NewReader(helperLoadBytes(t, "Program-BladeRun     ZON-1.18.syx")), ProgramT, location(refs[0]), ConflictAbort)
+
+	expected := []MemoryChange{
+		{ChangeAdded, ProgramT, location(refs[0]), location(refs[0]), "", "Blade run    ZON"},
+		{ChangeRenamed, ProgramT, location(refs[1]), location(refs[1]), a.at(refs[1]).PrintableName(), "Renamed         "},
+		{ChangeEdited, ProgramT, location(refs[2]), location(refs[2]), a.at(refs[2]).PrintableName(), a.at(refs[2]).PrintableName()},
+		{ChangeRemoved, ProgramT, location(refs[3]), location(refs[3]), a.at(refs[3]).PrintableName(), ""},
+		{ChangeMoved, ProgramT, location(refs[0]), location(blank), a.at(refs[0]).PrintableName(), a.at(refs[0]).PrintableName()},
+	}
+	changes := DiffMemory(a, b)
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %v", len(expected), changes)
+	}
+	for i, change := range changes {
+		if change != expected[i] {
+			t.Errorf("Expected %s, got %s", expected[i], change)
+		}
+	}
+}
+
+func TestDiffMemoryFollowsRenamedMoves(t *testing.T) {
+	a := populatedMemory(t, "Program-BladeRun     ZON-1.18.syx")
+	b := populatedMemory(t, "Program-BladeRun     ZON-1.18.syx")
+	dest := MemoryLocation{0, 0}
+	b.MovePrograms([]MemoryLocation{{validProgramBank, validProgramLocation}}, dest, ConflictAbort)
+	b.SetName(ProgramT, dest, "Blade moved")
+
+	changes := DiffMemory(a, b)
+	if len(changes) != 1 || changes[0].String() != `program     3:004 -> 0:000 moved "Blade run    ZON" -> "Blade moved     "` {
+		t.Errorf("Expected a single renamed move, got %v", changes)
+	}
+}
+
+func TestMergeMemory(t *testing.T) {
+	base := populatedMemory(t, "AllPrograms.syx")
+	refs := base.initializedRefs(ProgramT)
+	location := func(i int) MemoryLocation { return MemoryLocation{refs[i].bank(), refs[i].location()} }
+
+	ours := populatedMemory(t, "AllPrograms.syx")
+	ours.SetName(ProgramT, location(0), "Ours")
+	ours.DeleteProgram(location(1))
+	ours.SetName(ProgramT, location(2), "Same on both")
+
+	theirs := populatedMemory(t, "AllPrograms.syx")
+	theirs.SetName(ProgramT, location(0), "Theirs")
+	theirs.DeleteProgram(location(1))
+	theirs.SetName(ProgramT, location(2), "Same on both")
+	theirs.SetCategory(ProgramT, location(3), 2)
+
+	merged, conflicts := MergeMemory(base, ours, theirs)
+	if len(conflicts) != 1 || conflicts[0] != (MergeConflict{ProgramT, location(0), base.at(refs[0]).PrintableName(), "Ours            ", "Theirs          "}) {
+		t.Errorf("Expected a conflict over the first program, got %+v", conflicts)
+	}
+
+	titles := []string{"Ours", "", "Same on both", base.at(refs[3]).Title()}
+	for i, title := range titles {
+		program, _ := merged.GetProgram(location(i))
+		if program.Title() != title && !(title == "" && program == nil) {
+			t.Errorf("Expected %q at %v, got %v", title, location(i), program)
+		}
+	}
+	if program, _ := merged.GetProgram(location(3)); program.Category() != 2 {
+		t.Errorf("Expected their change of category to be merged")
+	}
+	if merged.NumPrograms(true) != base.NumPrograms(true)-1 {
+		t.Errorf("Expected %d programs in the merge, got %d", base.NumPrograms(true)-1, merged.NumPrograms(true))
+	}
+}
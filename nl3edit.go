@@ -34,6 +34,12 @@ func runCommands(memory *nordlead3.PatchMemory) {
 		switch command {
 		case "delete", "d", "clear", "c":
 			clear(memory, scanner, args[1:])
+		case "diff":
+			if tbll, ok := getArgs(args[1:], []string{"string", "int", "int", "int", "int"}); ok {
+				diff(memory, tbll[0].(string), ml(tbll[1].(int)-1, tbll[2].(int)-1), ml(tbll[3].(int)-1, tbll[4].(int)-1))
+			} else {
+				fmt.Println(" diff        <prog|perf> <bank> <location> <bank> <location> : list the parameters which differ")
+			}
 		case "export", "e":
 			export(memory, scanner, args[1:])
 		case "help", "h":
@@ -71,6 +77,37 @@ func runCommands(memory *nordlead3.PatchMemory) {
 			} else {
 				fmt.Println(" r | rename  <prog|perf> <bank> <location> <new name>    : rename the indicated program or performance")
 			}
+		case "spares":
+			if len(args) > 1 {
+				if typ, ok := ptype(args[1]); ok {
+					library := new(nordlead3.PatchLibrary)
+					library.AddMemory(memory)
+					for _, spare := range library.AnalyzeSpares(typ, 0.8) {
+						fmt.Println(spare)
+					}
+				}
+			} else {
+				fmt.Println(" spares      <prog|perf>                                 : report the spare bits which vary and what they follow")
+			}
+		case "stale":
+			if len(args) > 1 && args[1] == "repair" {
+				fmt.Printf("Repaired %d references.\n", memory.RepairReferences())
+			}
+			for _, stale := range memory.StaleReferences() {
+				fmt.Println(stale)
+			}
+		case "redo":
+			if description, err := memory.Redo(); err != nil {
+				fmt.Println(err)
+			} else {
+				fmt.Printf("Redid %s.\n", description)
+			}
+		case "undo", "u":
+			if description, err := memory.Undo(); err != nil {
+				fmt.Println(err)
+			} else {
+				fmt.Printf("Undid %s.\n", description)
+			}
 		default:
 			if len(args) > 0 {
 				fmt.Println("Invalid command. Enter h for help.")
@@ -272,6 +309,47 @@ func clear(memory *nordlead3.PatchMemory, scanner *bufio.Scanner, args []string)
 	}
 }
 
+func diff(memory *nordlead3.PatchMemory, typ string, a, b nordlead3.MemoryLocation) {
+	pt, ok := ptype(typ)
+	if !ok {
+		return
+	}
+
+	var changes []nordlead3.ParamChange
+	var err error
+	switch pt {
+	case nordlead3.PerformanceT:
+		pa, erra := memory.GetPerformance(a)
+		pb, errb := memory.GetPerformance(b)
+		if erra != nil || errb != nil {
+			fmt.Println("Both locations must hold a performance.")
+			return
+		}
+		fmt.Printf("%s -> %s\n", pa.Summary(), pb.Summary())
+		changes, err = nordlead3.DiffPerformances(pa, pb)
+	case nordlead3.ProgramT:
+		pa, erra := memory.GetProgram(a)
+		pb, errb := memory.GetProgram(b)
+		if erra != nil || errb != nil {
+			fmt.Println("Both locations must hold a program.")
+			return
+		}
+		fmt.Printf("%s -> %s\n", pa.Summary(), pb.Summary())
+		changes, err = nordlead3.DiffPrograms(pa, pb)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	if len(changes) == 0 {
+		fmt.Println("No parameters differ.")
+	}
+	for _, change := range changes {
+		fmt.Printf("  %s\n", change)
+	}
+}
+
 func export(memory *nordlead3.PatchMemory, scanner *bufio.Scanner, args []string) {
 	var err error
 
@@ -427,12 +505,17 @@ func loadFile(memory *nordlead3.PatchMemory, filename string) {
 	}
 	fmt.Printf("Opening %q\n", filename)
 
-	validFound, invalidFound, err := memory.Import(file, false)
+	report, err := memory.Import(file, nordlead3.ConflictSkip)
 	if err != nil {
 		panic(err)
 	}
 
-	fmt.Printf("Found %v valid SysEx entries (%v invalid).\n\n", validFound, invalidFound)
+	for _, entry := range report.Entries {
+		if entry.Err != nil {
+			fmt.Printf("Skipped %s at %#x from %s (%q): %s, %s\n", entry.PatchType, entry.Offset, entry.Source, entry.Name, entry.Outcome, entry.Err)
+		}
+	}
+	fmt.Printf("Found %v valid SysEx entries (%v invalid).\n\n", report.NumImported(), report.NumRejected())
 }
 
 func movePrompted(memory *nordlead3.PatchMemory, scanner *bufio.Scanner, typ nordlead3.PatchType) {
@@ -508,9 +591,9 @@ func movePrompted(memory *nordlead3.PatchMemory, scanner *bufio.Scanner, typ nor
 	}
 	switch typ {
 	case nordlead3.PerformanceT:
-		err = memory.MovePerformances(src, dest)
+		err = memory.MovePerformances(src, dest, askConflict(scanner))
 	case nordlead3.ProgramT:
-		err = memory.MovePrograms(src, dest)
+		err = memory.MovePrograms(src, dest, askConflict(scanner))
 	}
 	if err != nil {
 		fmt.Printf("Error moving %s: %q\n", typ.String(), err)
@@ -519,6 +602,29 @@ func movePrompted(memory *nordlead3.PatchMemory, scanner *bufio.Scanner, typ nor
 	}
 }
 
+// Asks what to do about each occupied destination.
+func askConflict(scanner *bufio.Scanner) nordlead3.ConflictFunc {
+	return func(c nordlead3.Conflict) nordlead3.ConflictAction {
+		fmt.Printf("%d:%d already holds %s\n", c.Destination.Bank+1, c.Destination.Location+1, c.Existing)
+		for {
+			args := getPrompted(fmt.Sprintf("Replace it with %s (o: overwrite, s: skip, n: next free in bank, a: abort)? ", c.Incoming), scanner)
+			if len(args) == 0 {
+				continue
+			}
+			switch args[0] {
+			case "o":
+				return nordlead3.ConflictOverwrite
+			case "s":
+				return nordlead3.ConflictSkip
+			case "n":
+				return nordlead3.ConflictNextFreeInBank
+			case "a":
+				return nordlead3.ConflictAbort
+			}
+		}
+	}
+}
+
 func rename(memory *nordlead3.PatchMemory, typ string, ml nordlead3.MemoryLocation, newName string) {
 	if pt, ok := ptype(typ); ok {
 		switch pt {
@@ -528,11 +634,11 @@ func rename(memory *nordlead3.PatchMemory, typ string, ml nordlead3.MemoryLocati
 				fmt.Printf("Performance %d:%d is not initialized.\n", ml.Bank+1, ml.Location+1)
 				break
 			}
-			err = p.SetName(newName)
-			if err != nil {
+			if err = memory.SetName(pt, ml, newName); err != nil {
 				fmt.Printf("Error renaming %d:%d (%q): %s", ml.Bank, ml.Location, p.PrintableName(), err)
 				return
 			}
+			p, _ = memory.GetPerformance(ml)
 			fmt.Println(p.Summary())
 		case nordlead3.ProgramT:
 			p, err := memory.GetProgram(ml)
@@ -540,11 +646,11 @@ func rename(memory *nordlead3.PatchMemory, typ string, ml nordlead3.MemoryLocati
 				fmt.Printf("Program %d:%d is not initialized.\n", ml.Bank+1, ml.Location+1)
 				break
 			}
-			err = p.SetName(newName)
-			if err != nil {
+			if err = memory.SetName(pt, ml, newName); err != nil {
 				fmt.Printf("Error renaming %d:%d (%q): %s", ml.Bank, ml.Location, p.PrintableName(), err)
 				return
 			}
+			p, _ = memory.GetProgram(ml)
 			fmt.Println(p.Summary())
 		}
 	}
@@ -575,10 +681,15 @@ func usage() {
 func help() {
 	fmt.Println("Available commands are: ")
 	fmt.Println(" help   | h                                              : print this help reference")
+	fmt.Println(" diff        <prog|perf> <bank> <location> <bank> <location> : list the parameters which differ")
 	exportHelp()
 	fmt.Println(" load   | l  <filename> [<filename> ...]                 : load the requested file into memory")
 	fmt.Println(" move   | m  <prog|perf>                                 : enter the move tool for programs or performances")
 	fmt.Println(" rename | r  <prog|perf> <bank> <location> <new name>    : rename the indicated program or performance")
+	fmt.Println(" spares      <prog|perf>                                 : report the spare bits which vary and what they follow")
+	fmt.Println(" stale       [repair]                                    : list (or first repair) stale performance slot references")
+	fmt.Println(" undo   | u                                              : undo the last change to memory")
+	fmt.Println(" redo                                                    : redo the last change undone")
 	fmt.Println(" perf        [<bank> <location>] [<depth>]               : print details of performance at that location")
 	fmt.Println(" prog        [<bank> <location>] [<depth>]               : print details of program at that location")
 }
@@ -4,11 +4,9 @@ package nordlead3
 TODO:
 
  - Try to keep NL3Edit at least rudimentarily functional.
- - Add a "library" mode to the patch memory, where it doesn't enforce bank sizes, allows performances to be categorized, and sorts always by name.
-   - Optionally have it sort by the author (split by space and only match if the last token is all caps)
-   - Probably easiest to do this by removing the arbitrary sizing on the performances and programs arrays and checking the limits explicitly when importing and moving.
- - Try to identify the difference between v1.18 and v1.20 Sysex and see if you can figure out where the missing arp sync settings are.
- - Add two sane init patches (an init and an initFM), both with the right sync bits set.
+ - Check the enum value names (see enums.go) against a unit.
+ - Dump requests are out of scope until their message types are documented (see sysex.go).
+ - Confirm against a unit running v1.18 whether it ignores any fields (see knownVersions); the bit positions match v1.20.
 */
 
 import (
@@ -47,18 +45,41 @@ var Categories = [15]string{
 }
 
 var (
-	ErrXferTypeMismatch   = errors.New("Cannot move different types of patches")
-	ErrInvalidLocation    = errors.New("Invalid location")
-	ErrUninitialized      = errors.New("That location is not initialized")
-	ErrInvalidCategory    = errors.New("Invalid category")
-	ErrInvalidName        = errors.New("Name cannot be blank nor exceed 16 characters")
-	ErrMemoryOccupied     = errors.New("One or more destination memory locations are not blank")
-	ErrMemoryOverflow     = errors.New("Not enough room in that bank")
-	ErrNoDataToWrite      = errors.New("No data to write to file")
-	ErrNoPerfCategory     = errors.New("Performances do not support categories.")
-	ErrImportTypeMismatch = errors.New("Sysex does not contain the right kind of patch (e.g. program when expecting performance).")
+	ErrXferTypeMismatch    = errors.New("Cannot move different types of patches")
+	ErrInvalidLocation     = errors.New("Invalid location")
+	ErrUninitialized       = errors.New("That location is not initialized")
+	ErrInvalidCategory     = errors.New("Invalid category")
+	ErrInvalidName         = errors.New("Name cannot be blank nor exceed 16 characters")
+	ErrInvalidAuthor       = errors.New("Author must be a single all-caps token no longer than 14 characters")
+	ErrMemoryOccupied      = errors.New("One or more destination memory locations are not blank")
+	ErrMemoryOverflow      = errors.New("Not enough room in that bank")
+	ErrNoDataToWrite       = errors.New("No data to write to file")
+	ErrNoPerfCategory      = errors.New("Performances do not support categories.")
+	ErrImportTypeMismatch  = errors.New("Sysex does not contain the right kind of patch (e.g. program when expecting performance).")
+	ErrUnsupportedVersion  = errors.New("Unsupported OS version")
+	ErrNoVersionConversion = errors.New("No known OS version to convert to from that one")
+	ErrTimeout             = errors.New("Timed out waiting for a MIDI message")
+	ErrTransportClosed     = errors.New("MIDI transport is closed")
+	ErrNotADump            = errors.New("Sysex is too short to contain a patch dump")
+	ErrInvalidValue        = errors.New("Value out of range for that parameter")
+	ErrUnknownParameter    = errors.New("No such parameter")
+	ErrInvalidSMF          = errors.New("Malformed or unsupported Standard MIDI File")
+	ErrUnknownDumpType     = errors.New("Sysex is not a program or performance dump")
+	ErrWrongLength         = errors.New("Dump is the wrong length for its type")
+	ErrChecksumMismatch    = errors.New("Dump checksum does not match its data")
+	ErrConflictAborted     = errors.New("Stopped at an occupied destination")
+	ErrNothingToUndo       = errors.New("Nothing to undo")
+	ErrNothingToRedo       = errors.New("Nothing to redo")
+	ErrAliasTaken          = errors.New("That name is already a parameter")
 )
 
+func categoryName(category uint8) string {
+	if category >= uint8(len(Categories)) {
+		return fmt.Sprintf("Unknown: %02x", category)
+	}
+	return Categories[category]
+}
+
 func populateStructFromBitstream(i interface{}, data []byte) error {
 	// Use reflection to get each field in the struct and it's length, then read that into it
 	rt := reflect.TypeOf(i).Elem()
@@ -202,12 +223,14 @@ func readBool(into reflect.Value, from *bitstream.BitReader) error {
 	return nil
 }
 
+// Signed fields are two's complement, so the top bit read is the sign.
 func readInt(into reflect.Value, from *bitstream.BitReader, length int) error {
 	bits, err := from.ReadBits(length)
 	if err != nil {
 		return err
 	}
-	into.SetInt(int64(bits))
+	shift := uint(64 - length)
+	into.SetInt(int64(bits<<shift) >> shift)
 
 	return nil
 }
@@ -312,6 +335,11 @@ func fprintReflectedField(writer io.Writer, sf reflect.StructField, rf reflect.V
 
 	fmt.Fprintf(writer, "  %s%-*s (%*s): ", strIndent, nameWidth, sf.Name, typeWidth, sf.Type)
 
+	if stringer, ok := rf.Interface().(fmt.Stringer); ok && rf.Kind() != reflect.Struct {
+		fmt.Fprintf(writer, "%s\n", stringer)
+		return
+	}
+
 	switch rf.Kind() {
 	case reflect.Int:
 		fmt.Fprintf(writer, "%#02x / %d", rf.Int(), rf.Int())
@@ -0,0 +1,232 @@
+package nordlead3
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// An editable parameter of a program or performance. Paths name a field of the patch data, descending
+// into embedded structs with dots and into arrays with an index, e.g. "Wheel_morph_params.Osc1_shape",
+// "Patch_data_b.Lfo1_rate" or "Chord_positions[3]". Switches are 0 or 1.
+type Parameter struct {
+	Path string
+	Min  int
+	Max  int
+}
+
+func (performance *Performance) Get(path string) (int, error) {
+	if performance == nil {
+		return 0, ErrUninitialized
+	}
+	return getParameter(reflect.ValueOf(performance.data).Elem(), path)
+}
+
+func (performance *Performance) Parameters() []Parameter {
+	return listParameters(reflect.ValueOf(new(PerformanceData)).Elem(), "", 0)
+}
+
+// Sets the parameter, returning a RangeError if the value lies outside its range.
+func (performance *Performance) Set(path string, value int) error {
+	if performance == nil {
+		return ErrUninitialized
+	}
+	return setParameter(reflect.ValueOf(performance.data).Elem(), path, value)
+}
+
+func (program *Program) Get(path string) (int, error) {
+	if program == nil {
+		return 0, ErrUninitialized
+	}
+	return getParameter(reflect.ValueOf(program.data).Elem(), path)
+}
+
+func (program *Program) Parameters() []Parameter {
+	return listParameters(reflect.ValueOf(new(ProgramData)).Elem(), "", 0)
+}
+
+// Sets the parameter, returning a RangeError if the value lies outside its range.
+func (program *Program) Set(path string, value int) error {
+	if program == nil {
+		return ErrUninitialized
+	}
+	return setParameter(reflect.ValueOf(program.data).Elem(), path, value)
+}
+
+// helpers
+
+func getParameter(root reflect.Value, path string) (int, error) {
+	rf, _, err := lookupParameter(root, path)
+	if err != nil {
+		if rf, alias, ok := lookupAlias(root, path); ok {
+			return alias.get(rf), nil
+		}
+		return 0, err
+	}
+	return fieldValue(rf), nil
+}
+
+func setParameter(root reflect.Value, path string, value int) error {
+	rf, parameter, err := lookupParameter(root, path)
+	if err != nil {
+		if rf, alias, ok := lookupAlias(root, path); ok {
+			if value < 0 || value > alias.mask() {
+				return RangeError{path, value, 0, alias.mask()}
+			}
+			alias.set(rf, value)
+			return nil
+		}
+		return err
+	}
+	if value < parameter.Min || value > parameter.Max {
+		return RangeError{path, value, parameter.Min, parameter.Max}
+	}
+
+	if rf.Kind() == reflect.Bool {
+		rf.SetBool(value == 1)
+	} else {
+		setNumber(rf, value)
+	}
+	return nil
+}
+
+// Switches read as 0 or 1.
+func fieldValue(rf reflect.Value) int {
+	switch rf.Kind() {
+	case reflect.Bool:
+		if rf.Bool() {
+			return 1
+		}
+		return 0
+	case reflect.Int:
+		return int(rf.Int())
+	default:
+		return int(rf.Uint())
+	}
+}
+
+// Resolves the path to the field it names. The version number is managed by Upgrade and Downgrade,
+// so it is not a parameter.
+func lookupParameter(root reflect.Value, path string) (reflect.Value, Parameter, error) {
+	rv := root
+	parts := strings.Split(path, ".")
+
+	for depth, part := range parts {
+		name, elem, isElem := splitElement(part)
+		last := depth == len(parts)-1
+
+		sf, ok := rv.Type().FieldByName(name)
+		if !ok || name == "Version_number" || skipField(sf, depth) {
+			break
+		}
+		rf := rv.FieldByIndex(sf.Index)
+
+		switch rf.Kind() {
+		case reflect.Struct:
+			if last || isElem {
+				return reflect.Value{}, Parameter{}, ErrUnknownParameter
+			}
+			rv = rf
+			continue
+		case reflect.Array:
+			if !isElem || elem >= rf.Len() {
+				return reflect.Value{}, Parameter{}, ErrUnknownParameter
+			}
+			rf = rf.Index(elem)
+		default:
+			if isElem {
+				return reflect.Value{}, Parameter{}, ErrUnknownParameter
+			}
+		}
+		if !last {
+			break
+		}
+
+		lo, hi := parameterRange(sf, rf.Kind())
+		return rf, Parameter{path, lo, hi}, nil
+	}
+	return reflect.Value{}, Parameter{}, ErrUnknownParameter
+}
+
+func listParameters(rv reflect.Value, prefix string, depth int) []Parameter {
+	var result []Parameter
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		rf := rv.Field(i)
+
+		if sf.Name == "Version_number" || skipField(sf, depth) {
+			continue
+		}
+		switch rf.Kind() {
+		case reflect.Struct:
+			result = append(result, listParameters(rf, prefix+sf.Name+".", depth+1)...)
+		case reflect.Array:
+			lo, hi := parameterRange(sf, rf.Type().Elem().Kind())
+			for elem := 0; elem < rf.Len(); elem++ {
+				result = append(result, Parameter{fmt.Sprintf("%s%s[%d]", prefix, sf.Name, elem), lo, hi})
+			}
+		default:
+			lo, hi := parameterRange(sf, rf.Kind())
+			result = append(result, Parameter{prefix + sf.Name, lo, hi})
+		}
+	}
+	return result
+}
+
+// The values of the parameters of rv, in the order listParameters lists them.
+func parameterValues(rv reflect.Value, depth int) []int {
+	var result []int
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		rf := rv.Field(i)
+
+		if sf.Name == "Version_number" || skipField(sf, depth) {
+			continue
+		}
+		switch rf.Kind() {
+		case reflect.Struct:
+			result = append(result, parameterValues(rf, depth+1)...)
+		case reflect.Array:
+			for elem := 0; elem < rf.Len(); elem++ {
+				result = append(result, fieldValue(rf.Index(elem)))
+			}
+		default:
+			result = append(result, fieldValue(rf))
+		}
+	}
+	return result
+}
+
+// The range given by the tags, or else whatever fits in the field's bits.
+func parameterRange(sf reflect.StructField, kind reflect.Kind) (lo int, hi int) {
+	if kind == reflect.Bool {
+		return 0, 1
+	}
+	if lo, hi, ok := fieldRange(sf); ok {
+		return lo, hi
+	}
+
+	length, _ := strconv.Atoi(sf.Tag.Get("len"))
+	if kind == reflect.Int {
+		return -(1 << uint(length-1)), 1<<uint(length-1) - 1
+	}
+	return 0, 1<<uint(length) - 1
+}
+
+// Splits "Chord_positions[3]" into its field name and element index.
+func splitElement(part string) (name string, elem int, isElem bool) {
+	open := strings.Index(part, "[")
+	if open < 0 || !strings.HasSuffix(part, "]") {
+		return part, 0, false
+	}
+	elem, err := strconv.Atoi(part[open+1 : len(part)-1])
+	if err != nil || elem < 0 {
+		return part, 0, false // not a field name either, so the lookup fails
+	}
+	return part[:open], elem, true
+}
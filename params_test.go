@@ -0,0 +1,95 @@
+package nordlead3
+
+import (
+	"testing"
+)
+
+func TestGetAndSetProgramParameters(t *testing.T) {
+	program := NewInitProgram()
+
+	cases := []struct {
+		path  string
+		value int
+	}{
+		{"Filt_frequency1", 64},
+		{"Wheel_morph_params.Osc1_shape", -20},
+		{"Chord_positions[3]", 12},
+		{"Osc1_waveform", int(WaveformSine)},
+		{"Mono_mode", 1},
+	}
+
+	for _, c := range cases {
+		if err := program.Set(c.path, c.value); err != nil {
+			t.Errorf("Set(%q, %d): %v", c.path, c.value, err)
+		}
+		if value, err := program.Get(c.path); value != c.value || err != nil {
+			t.Errorf("Get(%q): expected %d, got %d (%v)", c.path, c.value, value, err)
+		}
+	}
+	if program.data.Osc1_waveform != WaveformSine || program.data.Wheel_morph_params.Osc1_shape != -20 || !program.data.Mono_mode {
+		t.Errorf("Set did not reach the program data")
+	}
+}
+
+func TestSetRejectsInvalidParameters(t *testing.T) {
+	program := NewInitProgram()
+
+	if err := program.Set("Filt1_slope", 3); err != (RangeError{"Filt1_slope", 3, 0, 2}) {
+		t.Errorf("Expected a RangeError for Filt1_slope 3, got %v", err)
+	}
+	if err := program.Set("Kbd_morph_params.Oscmix", 128); err == nil {
+		t.Errorf("Expected a RangeError for a morph of 128")
+	}
+	if err := program.Set("Spare3", 4); err == nil {
+		t.Errorf("Expected a RangeError for a 2-bit value of 4")
+	}
+
+	for _, path := range []string{"Filt_frequency3", "Version_number", "Wheel_morph_params", "Chord_positions[24]", "Chord_positions", "Oscmix[0]", "Oscmix.Level"} {
+		if _, err := program.Get(path); err != ErrUnknownParameter {
+			t.Errorf("Get(%q): expected ErrUnknownParameter, got %v", path, err)
+		}
+	}
+}
+
+func TestPerformanceParameters(t *testing.T) {
+	performance := NewInitPerformance()
+
+	if err := performance.Set("Patch_data_b.Lfo1_rate", 99); err != nil {
+		t.Fatal(err)
+	}
+	if performance.data.Patch_data_b.Lfo1_rate != 99 || performance.data.Patch_data_a.Lfo1_rate == 99 {
+		t.Errorf("Set did not reach slot B alone")
+	}
+	if _, err := performance.Get("Patch_data_b.Version_number"); err != ErrUnknownParameter {
+		t.Errorf("Embedded programs have no version number, got %v", err)
+	}
+
+	found := false
+	for _, parameter := range performance.Parameters() {
+		if parameter == (Parameter{"Patch_data_d.Velocity_morph_params.Output_level", -128, 127}) {
+			found = true
+		}
+		if _, err := performance.Get(parameter.Path); err != nil {
+			t.Errorf("Listed parameter %q cannot be read: %v", parameter.Path, err)
+		}
+	}
+	if !found {
+		t.Errorf("Expected the slot D velocity morph of the output level to be listed")
+	}
+}
+
+func TestCopyDoesNotShareData(t *testing.T) {
+	memory := new(PatchMemory)
+	ml := MemoryLocation{0, 0}
+	memory.set(patchRef{ProgramT, MemoryT, ml.index()}, NewInitProgram())
+	if err := memory.CopyProgramToSlot(ml, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	copied, _ := memory.GetSlotProgram(0)
+	copied.Set("Filt_resonance", 100)
+	original, _ := memory.GetProgram(ml)
+	if value, _ := original.Get("Filt_resonance"); value == 100 {
+		t.Errorf("Editing a copy changed the original")
+	}
+}
@@ -1,12 +1,15 @@
 package nordlead3
 
 type patch interface {
+	Author() string
+	ContentHash() PatchHash
 	SetCategory(int) error
 	SetName(string) error
 	PrintContents(int)
 	PrintableCategory() string
 	PrintableName() string
 	Summary() string
+	Title() string
 	Version() float64
 	PatchType() PatchType
 }
@@ -0,0 +1,309 @@
+package nordlead3
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// A PatchLibrary is an unbounded collection of programs and performances, such as a master library
+// gathered from several units. It does not enforce bank sizes: patches are always kept grouped by
+// category and sorted by name. The limits of the unit only apply when patches are placed into a
+// PatchMemory or exported as bank dumps.
+type PatchLibrary struct {
+	performances []*Performance
+	programs     []*Program
+}
+
+// Adds copies of every patch in the memory, so that later edits to the memory leave the library as it was.
+func (library *PatchLibrary) AddMemory(memory *PatchMemory) error {
+	if memory == nil {
+		return ErrUninitialized
+	}
+	for _, performance := range memory.performances {
+		if performance == nil {
+			continue
+		}
+		if err := library.AddPerformance(performance); err != nil {
+			return err
+		}
+	}
+	for _, program := range memory.programs {
+		if program == nil {
+			continue
+		}
+		if err := library.AddProgram(program); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Adds a copy of the performance.
+func (library *PatchLibrary) AddPerformance(performance *Performance) error {
+	if performance == nil {
+		return ErrUninitialized
+	}
+	library.performances = append(library.performances, performance.clone())
+	return nil
+}
+
+// Adds a copy of the program.
+func (library *PatchLibrary) AddProgram(program *Program) error {
+	if program == nil {
+		return ErrUninitialized
+	}
+	library.programs = append(library.programs, program.clone())
+	return nil
+}
+
+// Exports the performances which would occupy the given bank if the library were laid out
+// in order on a unit. Only NumPerformanceBanks banks can be exported.
+func (library *PatchLibrary) ExportPerformanceBank(bank int, writer io.Writer) error {
+	if bank < 0 || bank >= NumPerformanceBanks {
+		return ErrInvalidLocation
+	}
+	memory := new(PatchMemory)
+	err := library.PlacePerformances(memory, library.performanceBank(bank), MemoryLocation{bank, 0})
+	if err != nil {
+		return err
+	}
+	return memory.ExportPerformanceBank(bank, writer)
+}
+
+// Exports the programs which would occupy the given bank if the library were laid out
+// in order on a unit. Only NumProgramBanks banks can be exported.
+func (library *PatchLibrary) ExportProgramBank(bank int, writer io.Writer) error {
+	if bank < 0 || bank >= NumProgramBanks {
+		return ErrInvalidLocation
+	}
+	memory := new(PatchMemory)
+	err := library.PlacePrograms(memory, library.programBank(bank), MemoryLocation{bank, 0})
+	if err != nil {
+		return err
+	}
+	return memory.ExportProgramBank(bank, writer)
+}
+
+// Loads every program and performance found in the datastream, regardless of the location it was dumped from.
+func (library *PatchLibrary) Import(input io.Reader) (numValid int, numInvalid int, err error) {
+	validFound, invalidFound := 0, 0
+	if input, err = sysexStream(input); err != nil {
+		return 0, 0, err
+	}
+	scanner := bufio.NewScanner(input)
+	scanner.Split(splitSysex(vendorNord, modelNL3))
+
+	for scanner.Scan() {
+		sysex, err := parseSysex(scanner.Bytes())
+		if err != nil {
+			invalidFound++
+			continue
+		}
+
+		switch sysex.patchType() {
+		case ProgramT:
+			var program *Program
+			if program, err = newProgramFromSysex(sysex); err == nil {
+				err = library.AddProgram(program)
+			}
+		case PerformanceT:
+			var performance *Performance
+			if performance, err = newPerformanceFromSysex(sysex); err == nil {
+				err = library.AddPerformance(performance)
+			}
+		}
+
+		if err == nil {
+			validFound++
+		} else {
+			invalidFound++
+		}
+	}
+	return validFound, invalidFound, scanner.Err()
+}
+
+func (library *PatchLibrary) NumPerformances() int {
+	return len(library.performances)
+}
+
+func (library *PatchLibrary) NumPrograms() int {
+	return len(library.programs)
+}
+
+// Returns the performances in library order (by category, then name).
+func (library *PatchLibrary) Performances() []*Performance {
+	library.sort()
+	return append([]*Performance(nil), library.performances...)
+}
+
+func (library *PatchLibrary) PerformancesByAuthor(author string) []*Performance {
+	var result []*Performance
+
+	for _, performance := range library.Performances() {
+		if performance.Author() == author {
+			result = append(result, performance)
+		}
+	}
+	return result
+}
+
+func (library *PatchLibrary) PerformancesInCategory(category int) []*Performance {
+	var result []*Performance
+
+	for _, performance := range library.Performances() {
+		if int(performance.category) == category {
+			result = append(result, performance)
+		}
+	}
+	return result
+}
+
+// Copies the given performances into consecutive locations of memory starting at dest.
+// Nothing is placed if the performances would overflow the unit's banks or any destination is occupied.
+func (library *PatchLibrary) PlacePerformances(memory *PatchMemory, performances []*Performance, dest MemoryLocation) error {
+	var patches []patch
+	for _, performance := range performances {
+		patches = append(patches, performance)
+	}
+	return memory.place(patches, patchRef{PerformanceT, MemoryT, dest.index()})
+}
+
+// Copies the given programs into consecutive locations of memory starting at dest.
+// Nothing is placed if the programs would overflow the unit's banks or any destination is occupied.
+func (library *PatchLibrary) PlacePrograms(memory *PatchMemory, programs []*Program, dest MemoryLocation) error {
+	var patches []patch
+	for _, program := range programs {
+		patches = append(patches, program)
+	}
+	return memory.place(patches, patchRef{ProgramT, MemoryT, dest.index()})
+}
+
+// Returns the programs in library order (by category, then name).
+func (library *PatchLibrary) Programs() []*Program {
+	library.sort()
+	return append([]*Program(nil), library.programs...)
+}
+
+func (library *PatchLibrary) ProgramsByAuthor(author string) []*Program {
+	var result []*Program
+
+	for _, program := range library.Programs() {
+		if program.Author() == author {
+			result = append(result, program)
+		}
+	}
+	return result
+}
+
+func (library *PatchLibrary) ProgramsInCategory(category int) []*Program {
+	var result []*Program
+
+	for _, program := range library.Programs() {
+		if int(program.category) == category {
+			result = append(result, program)
+		}
+	}
+	return result
+}
+
+func (library *PatchLibrary) RemovePerformance(performance *Performance) bool {
+	for i, curr := range library.performances {
+		if curr == performance {
+			library.performances = append(library.performances[:i], library.performances[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (library *PatchLibrary) RemoveProgram(program *Program) bool {
+	for i, curr := range library.programs {
+		if curr == program {
+			library.programs = append(library.programs[:i], library.programs[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Performances on the unit do not support categories, but the library allows them to be categorized.
+func (library *PatchLibrary) SetPerformanceCategory(performance *Performance, newCategory int) error {
+	if performance == nil {
+		return ErrUninitialized
+	}
+	if newCategory < 0 || newCategory >= len(Categories) {
+		return ErrInvalidCategory
+	}
+	performance.category = uint8(newCategory)
+	return nil
+}
+
+func (library *PatchLibrary) SprintPerformances() string {
+	var result []string
+	currCategory := -1 // won't match any category
+
+	result = append(result, "\n***** PERFORMANCES ******\n")
+
+	for _, performance := range library.Performances() {
+		if int(performance.category) != currCategory {
+			currCategory = int(performance.category)
+			result = append(result, fmt.Sprintf("\n*** %s ***", categoryName(performance.category)))
+		}
+		result = append(result, fmt.Sprintf("   %s", performance.Summary()))
+	}
+
+	return strings.Join(result, "\n")
+}
+
+func (library *PatchLibrary) SprintPrograms() string {
+	var result []string
+	currCategory := -1 // won't match any category
+
+	result = append(result, "\n***** PROGRAMS ******\n")
+
+	for _, program := range library.Programs() {
+		if int(program.category) != currCategory {
+			currCategory = int(program.category)
+			result = append(result, fmt.Sprintf("\n*** %s ***", categoryName(program.category)))
+		}
+		result = append(result, fmt.Sprintf("   %s", program.Summary()))
+	}
+
+	return strings.Join(result, "\n")
+}
+
+// helpers
+
+// Returns the performances which fall into the given bank when the library is laid out in order.
+func (library *PatchLibrary) performanceBank(bank int) []*Performance {
+	performances := library.Performances()
+	start, end := min(bank*BankSize, len(performances)), min((bank+1)*BankSize, len(performances))
+	return performances[start:end]
+}
+
+// Returns the programs which fall into the given bank when the library is laid out in order.
+func (library *PatchLibrary) programBank(bank int) []*Program {
+	programs := library.Programs()
+	start, end := min(bank*BankSize, len(programs)), min((bank+1)*BankSize, len(programs))
+	return programs[start:end]
+}
+
+// Names can be changed after patches are added, so the order is restored whenever it is needed.
+func (library *PatchLibrary) sort() {
+	sort.SliceStable(library.performances, func(i, j int) bool {
+		return libraryLess(library.performances[i], library.performances[j])
+	})
+	sort.SliceStable(library.programs, func(i, j int) bool {
+		return libraryLess(library.programs[i], library.programs[j])
+	})
+}
+
+func libraryLess(a, b sysexable) bool {
+	if a.sysexCategory() != b.sysexCategory() {
+		return a.sysexCategory() < b.sysexCategory()
+	}
+	return strings.ToLower(string(a.sysexName())) < strings.ToLower(string(b.sysexName()))
+}
@@ -0,0 +1,130 @@
+package nordlead3
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLibraryImportUnbounded(t *testing.T) {
+	library := populatedLibrary(t, "AllPrograms.syx", "AllFactoryPrograms1.20RevA.syx", "AllPerformances.syx")
+
+	if library.NumPrograms() <= NumProgramBanks*BankSize {
+		t.Errorf("Expected the library to hold more than %d programs, got %d", NumProgramBanks*BankSize, library.NumPrograms())
+	}
+	if library.NumPerformances() == 0 {
+		t.Errorf("Expected the library to hold performances")
+	}
+}
+
+func TestLibraryAddMemoryCopies(t *testing.T) {
+	memory := populatedMemory(t, "Program-BladeRun     ZON-1.18.syx")
+	library := new(PatchLibrary)
+
+	if err := library.AddMemory(memory); err != nil || library.NumPrograms() != 1 {
+		t.Fatalf("Expected the program to be added, got %d (%v)", library.NumPrograms(), err)
+	}
+	program, _ := memory.GetProgram(MemoryLocation{validProgramBank, validProgramLocation})
+	program.SetName("Edited")
+	if library.Programs()[0].PrintableName() != validProgramName {
+		t.Errorf("Expected editing the memory to leave the library untouched")
+	}
+	if err := library.AddMemory(nil); err != ErrUninitialized {
+		t.Errorf("Expected ErrUninitialized for a nil memory, got %v", err)
+	}
+}
+
+func TestLibraryOrder(t *testing.T) {
+	library := populatedLibrary(t, "ProgBank1.syx")
+	programs := library.Programs()
+
+	for i := 1; i < len(programs); i++ {
+		if libraryLess(programs[i], programs[i-1]) {
+			t.Errorf("Programs out of order: %q (%s) sorted after %q (%s)", programs[i-1].PrintableName(), programs[i-1].PrintableCategory(), programs[i].PrintableName(), programs[i].PrintableCategory())
+		}
+	}
+
+	// Renaming or recategorizing a program must not break the ordering
+	programs[0].SetName("zzzz Last")
+	programs[0].SetCategory(len(Categories) - 1)
+	programs = library.Programs()
+	if programs[len(programs)-1].PrintableName() != "zzzz Last       " {
+		t.Errorf("Library did not re-sort after rename, last program is %q", programs[len(programs)-1].PrintableName())
+	}
+
+	for category := range Categories {
+		for _, program := range library.ProgramsInCategory(category) {
+			if program.Category() != category {
+				t.Errorf("ProgramsInCategory(%d) returned %q in category %d", category, program.PrintableName(), program.Category())
+			}
+		}
+	}
+}
+
+func TestLibraryPerformanceCategory(t *testing.T) {
+	library := populatedLibrary(t, "PerfBank1.syx")
+	performance := library.Performances()[0]
+
+	if err := library.SetPerformanceCategory(performance, 0x0B); err != nil {
+		t.Fatalf("Unexpected error categorizing performance: %s", err)
+	}
+	found := false
+	for _, p := range library.PerformancesInCategory(0x0B) {
+		found = found || p == performance
+	}
+	if !found {
+		t.Errorf("Categorized performance not found in its category")
+	}
+	if err := library.SetPerformanceCategory(performance, len(Categories)); err != ErrInvalidCategory {
+		t.Errorf("Expected ErrInvalidCategory, got %v", err)
+	}
+}
+
+func TestLibraryExportBank(t *testing.T) {
+	library := populatedLibrary(t, "AllPrograms.syx", "AllFactoryPrograms1.20RevA.syx")
+
+	var buf bytes.Buffer
+	if err := library.ExportProgramBank(0, &buf); err != nil {
+		t.Fatalf("Unexpected error exporting bank: %s", err)
+	}
+	memory := new(PatchMemory)
+	helperLoadFromSysex(t, memory, buf.Bytes())
+	if memory.numInitialized(ProgramT, 0) != BankSize {
+		t.Errorf("Expected a full bank of %d programs, got %d", BankSize, memory.numInitialized(ProgramT, 0))
+	}
+
+	if err := library.ExportProgramBank(NumProgramBanks, &buf); err != ErrInvalidLocation {
+		t.Errorf("Expected ErrInvalidLocation exporting beyond the unit's banks, got %v", err)
+	}
+}
+
+func TestLibraryPlacePrograms(t *testing.T) {
+	library := populatedLibrary(t, "ProgBank1.syx")
+	memory := populatedMemory(t, "ProgBank1.syx")
+	programs := library.Programs()[:10]
+
+	// Overflowing the last bank places nothing
+	last := MemoryLocation{NumProgramBanks - 1, BankSize - 5}
+	if err := library.PlacePrograms(memory, programs, last); err != ErrMemoryOverflow {
+		t.Errorf("Expected ErrMemoryOverflow, got %v", err)
+	}
+	requireUninitialized(t, memory, patchRef{ProgramT, MemoryT, last.index()})
+
+	// Occupied destinations place nothing
+	if err := library.PlacePrograms(memory, programs, MemoryLocation{0, 0}); err != ErrMemoryOccupied {
+		t.Errorf("Expected ErrMemoryOccupied, got %v", err)
+	}
+
+	dest := MemoryLocation{1, 0}
+	if err := library.PlacePrograms(memory, programs, dest); err != nil {
+		t.Fatalf("Unexpected error placing programs: %s", err)
+	}
+	for i, program := range programs {
+		placed, err := memory.get(patchRef{ProgramT, MemoryT, dest.index() + i})
+		if err != nil {
+			t.Fatalf("Placed program %d missing: %s", i, err)
+		}
+		if placed == program || placed.Summary() != program.Summary() {
+			t.Errorf("Expected a copy of %s, got %s", program.Summary(), placed.Summary())
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 )
 
@@ -30,6 +31,25 @@ type PatchMemory struct {
 	programs        [NumProgramBanks * BankSize]*Program
 	slotPerformance *Performance
 	slotPrograms    [4]*Program
+	rangePolicy     RangePolicy
+	history         history
+	trackReferences bool
+}
+
+// Lists the distinct authors of the patches of the given type in memory, in alphabetical order.
+func (memory *PatchMemory) Authors(pt PatchType) []string {
+	var result []string
+	seen := make(map[string]bool)
+
+	for _, ref := range memory.initializedRefs(pt) {
+		patch, _ := memory.get(ref)
+		if author := patch.Author(); author != "" && !seen[author] {
+			seen[author] = true
+			result = append(result, author)
+		}
+	}
+	sort.Strings(result)
+	return result
 }
 
 func (memory *PatchMemory) CopyPerformanceToSlot(ml MemoryLocation) error {
@@ -58,12 +78,18 @@ func (memory *PatchMemory) CopySlotToProgram(index int, ml MemoryLocation) error
 
 func (memory *PatchMemory) DeletePerformance(ml MemoryLocation) {
 	ref := patchRef{PerformanceT, MemoryT, ml.index()}
-	memory.clear(ref)
+	memory.record(describe("delete", ref), func() error {
+		memory.clear(ref)
+		return nil
+	})
 }
 
 func (memory *PatchMemory) DeleteProgram(ml MemoryLocation) {
 	ref := patchRef{ProgramT, MemoryT, ml.index()}
-	memory.clear(ref)
+	memory.record(describe("delete", ref), func() error {
+		memory.clear(ref)
+		return nil
+	})
 }
 
 func (memory *PatchMemory) ExportAllPerformances(writer io.Writer) error {
@@ -90,11 +116,7 @@ func (memory *PatchMemory) ExportPerformance(ml MemoryLocation, writer io.Writer
 }
 
 func (memory *PatchMemory) ExportPerformanceAsSlot(ml MemoryLocation, writer io.Writer) error {
-	origSlotContents := memory.slotPerformance
-	memory.CopyPerformanceToSlot(ml)
-	err := memory.exportLocations([]patchRef{performanceSlotRef}, writer)
-	memory.slotPerformance = origSlotContents
-	return err
+	return memory.exportAs(patchRef{PerformanceT, MemoryT, ml.index()}, performanceSlotRef, writer)
 }
 
 func (memory *PatchMemory) ExportPerformanceBank(bank int, writer io.Writer) error {
@@ -116,11 +138,7 @@ func (memory *PatchMemory) ExportProgram(ml MemoryLocation, writer io.Writer) er
 }
 
 func (memory *PatchMemory) ExportProgramAsSlot(ml MemoryLocation, slot int, writer io.Writer) error {
-	origSlotContents := memory.slotPrograms[slot]
-	memory.CopyProgramToSlot(ml, slot)
-	err := memory.exportLocations([]patchRef{patchRef{ProgramT, SlotT, slot}}, writer)
-	memory.slotPrograms[slot] = origSlotContents // put it back
-	return err
+	return memory.exportAs(patchRef{ProgramT, MemoryT, ml.index()}, patchRef{ProgramT, SlotT, slot}, writer)
 }
 
 func (memory *PatchMemory) ExportProgramBank(bank int, writer io.Writer) error {
@@ -137,61 +155,27 @@ func (memory *PatchMemory) ExportProgramSlot(slot int, writer io.Writer) error {
 }
 
 // Straight import: try to load into patch memory the way the file was dumped out, preserving
-// locations from the sysex.
-func (memory *PatchMemory) Import(input io.Reader, overwrite bool) (numValid int, numInvalid int, err error) {
-	validFound, invalidFound := 0, 0
-	scanner := bufio.NewScanner(input)
-	scanner.Split(splitSysex(vendorNord, modelNL3))
-
-	for ok := scanner.Scan(); ; ok = scanner.Scan() {
-		if !ok {
-			return validFound, invalidFound, scanner.Err()
-		}
-		sysex, err := parseSysex(scanner.Bytes())
-		if err != nil {
-			invalidFound++
-			continue
-		}
-
-		err = memory.importTo(sysex, sysex.toPatchRef(), overwrite)
-		if err == nil {
-			validFound++
-		} else {
-			invalidFound++
-		}
-	}
-	return validFound, invalidFound, nil
+// locations from the sysex. Standard MIDI Files are unwrapped first. The report lists every NL3 message
+// found, whether or not it could be loaded. Occupied locations are handled as the policy decides.
+func (memory *PatchMemory) Import(input io.Reader, policy ConflictPolicy) (report *ImportReport, err error) {
+	memory.record("import", func() error {
+		report, err = memory.importStream(input, policy, nil)
+		return err
+	})
+	return report, err
 }
 
 // Custom import: loads the patches of the designated type only, starting at the memory location given.
-// Subsequent patches found in the same datastream will populate subsequent data locations.
-// Data in memory can be lost if overwrite is set to true and there are loaded patches in the locations populated by the import.
-func (memory *PatchMemory) ImportTo(input io.Reader, pt PatchType, ml MemoryLocation, overwrite bool) (numImported, numRejected int, err error) {
-	validFound, invalidFound := 0, 0
+// Subsequent patches found in the same datastream will populate the locations following wherever the
+// previous patch was placed.
+// Data in memory can be lost if the policy overwrites the patches in the locations populated by the import.
+func (memory *PatchMemory) ImportTo(input io.Reader, pt PatchType, ml MemoryLocation, policy ConflictPolicy) (report *ImportReport, err error) {
 	dest := patchRef{pt, MemoryT, ml.index()}
-
-	scanner := bufio.NewScanner(input)
-	scanner.Split(splitSysex(vendorNord, modelNL3))
-
-	for ok := scanner.Scan(); ; ok = scanner.Scan() {
-		if !ok {
-			return validFound, invalidFound, scanner.Err()
-		}
-		sysex, err := parseSysex(scanner.Bytes())
-		if err != nil {
-			invalidFound++
-			continue
-		}
-
-		err = memory.importTo(sysex, dest, overwrite)
-		if err == nil {
-			validFound++
-			dest = dest.increment()
-		} else {
-			invalidFound++
-		}
-	}
-	return validFound, invalidFound, nil
+	memory.record(describe("import to", dest), func() error {
+		report, err = memory.importStream(input, policy, &dest)
+		return err
+	})
+	return report, err
 }
 
 func (memory *PatchMemory) GetPerformance(ml MemoryLocation) (*Performance, error) {
@@ -230,22 +214,42 @@ func (memory *PatchMemory) GetSlotProgram(index int) (*Program, error) {
 	return patch.(*Program), nil
 }
 
-func (memory *PatchMemory) MovePerformances(src []MemoryLocation, dest MemoryLocation) error {
+// Returns the locations of all patches of the given type tagged with author.
+func (memory *PatchMemory) LocationsByAuthor(pt PatchType, author string) []MemoryLocation {
+	var result []MemoryLocation
+
+	for _, ref := range memory.initializedRefs(pt) {
+		if patch, _ := memory.get(ref); patch.Author() == author {
+			result = append(result, MemoryLocation{ref.bank(), ref.location()})
+		}
+	}
+	return result
+}
+
+// Moves the performances to consecutive locations starting at dest, resolving occupied ones with the policy.
+func (memory *PatchMemory) MovePerformances(src []MemoryLocation, dest MemoryLocation, policy ConflictPolicy) error {
 	var refs []patchRef
 	for _, ml := range src {
 		refs = append(refs, patchRef{PerformanceT, MemoryT, ml.index()})
 	}
 	destref := patchRef{PerformanceT, MemoryT, dest.index()}
-	return memory.transfer(refs, destref, moveM)
+	return memory.record(describe(fmt.Sprintf("move %d to", len(refs)), destref), func() error {
+		return memory.transfer(refs, destref, moveM, policy)
+	})
 }
 
-func (memory *PatchMemory) MovePrograms(mls []MemoryLocation, dest MemoryLocation) error {
+// Moves the programs to consecutive locations starting at dest, resolving occupied ones with the policy.
+func (memory *PatchMemory) MovePrograms(mls []MemoryLocation, dest MemoryLocation, policy ConflictPolicy) error {
 	var refs []patchRef
 	for _, ml := range mls {
 		refs = append(refs, patchRef{ProgramT, MemoryT, ml.index()})
 	}
 	destref := patchRef{ProgramT, MemoryT, dest.index()}
-	return memory.transfer(refs, destref, moveM)
+	return memory.record(describe(fmt.Sprintf("move %d to", len(refs)), destref), func() error {
+		return memory.followPrograms(nil, func() error {
+			return memory.transfer(refs, destref, moveM, policy)
+		})
+	})
 }
 
 func (memory *PatchMemory) NumPerformances(onlyInitialized bool) int {
@@ -276,6 +280,73 @@ func (memory *PatchMemory) NumPrograms(onlyInitialized bool) int {
 	return result
 }
 
+// Re-stamps all the patches at the given locations with the author tag. Either all of them are
+// re-stamped or, if any of them cannot be, none are.
+func (memory *PatchMemory) SetAuthor(pt PatchType, mls []MemoryLocation, author string) error {
+	var refs []patchRef
+	var renamed []patch
+
+	for _, ml := range mls {
+		ref := patchRef{pt, MemoryT, ml.index()}
+		original, err := memory.get(ref)
+		if err != nil {
+			return err
+		}
+		newName, err := stampAuthor(original.Title(), author)
+		if err != nil {
+			return err
+		}
+		patch := clonePatch(original)
+		patch.SetName(newName)
+		refs = append(refs, ref)
+		renamed = append(renamed, patch)
+	}
+
+	return memory.record(fmt.Sprintf("set author of %d %ss", len(refs), pt), func() error {
+		for i, ref := range refs {
+			memory.put(ref, renamed[i])
+		}
+		return nil
+	})
+}
+
+// Sets the category of the program at ml. Performances have no category.
+func (memory *PatchMemory) SetCategory(pt PatchType, ml MemoryLocation, category int) error {
+	return memory.modify(patchRef{pt, MemoryT, ml.index()}, "set category of", func(p patch) error {
+		return p.SetCategory(category)
+	})
+}
+
+func (memory *PatchMemory) SetName(pt PatchType, ml MemoryLocation, name string) error {
+	return memory.modify(patchRef{pt, MemoryT, ml.index()}, "rename", func(p patch) error {
+		return p.SetName(name)
+	})
+}
+
+// Returns the locations of all patches of the given type, ordered by author and then by title.
+// Patches without an author come last.
+func (memory *PatchMemory) SortByAuthor(pt PatchType) []MemoryLocation {
+	refs := memory.initializedRefs(pt)
+
+	sort.SliceStable(refs, func(i, j int) bool {
+		a, _ := memory.get(refs[i])
+		b, _ := memory.get(refs[j])
+		if a.Author() != b.Author() {
+			if a.Author() == "" || b.Author() == "" {
+				return b.Author() == ""
+			}
+			return a.Author() < b.Author()
+		}
+		return a.Title() < b.Title()
+	})
+
+	var result []MemoryLocation
+	for _, ref := range refs {
+		result = append(result, MemoryLocation{ref.bank(), ref.location()})
+	}
+	return result
+}
+
 func (memory *PatchMemory) SprintPrograms(omitBlank bool) string {
 	var result []string
 	currBank := -1 // won't match any bank
@@ -330,25 +401,26 @@ func (memory *PatchMemory) SprintPerformances(omitBlank bool) string {
 func (memory *PatchMemory) SwapPerformances(a MemoryLocation, b MemoryLocation) error {
 	aref := patchRef{PerformanceT, MemoryT, a.index()}
 	bref := patchRef{PerformanceT, MemoryT, b.index()}
-	return memory.swap(aref, bref)
+	return memory.record(describe("swap", aref)+" with "+bref.patchLocation().String(), func() error {
+		return memory.swap(aref, bref)
+	})
 }
 
 func (memory *PatchMemory) SwapPrograms(a MemoryLocation, b MemoryLocation) error {
 	aref := patchRef{ProgramT, MemoryT, a.index()}
 	bref := patchRef{ProgramT, MemoryT, b.index()}
-	return memory.swap(aref, bref)
+	return memory.record(describe("swap", aref)+" with "+bref.patchLocation().String(), func() error {
+		return memory.followPrograms(nil, func() error {
+			return memory.swap(aref, bref)
+		})
+	})
 }
 
 // Core internal behaviours
 
 func (memory *PatchMemory) clear(ref patchRef) {
 	if memory.initialized(ref) {
-		switch ref.patchType {
-		case PerformanceT:
-			*memory.perfPtr(ref) = nil
-		case ProgramT:
-			*memory.progPtr(ref) = nil
-		}
+		memory.put(ref, nil)
 	}
 }
 
@@ -366,19 +438,9 @@ func (memory *PatchMemory) copy(src patchRef, dest patchRef) error {
 		return ErrMemoryOccupied // allow overwriting slots silently, they're temporary
 	}
 
-	switch src.patchType {
-	case PerformanceT:
-		srcPtr := memory.perfPtr(src)
-		destPtr := memory.perfPtr(dest)
-		copy := **srcPtr
-		*destPtr = &copy
-	case ProgramT:
-		srcPtr := memory.progPtr(src)
-		destPtr := memory.progPtr(dest)
-		copy := **srcPtr
-		*destPtr = &copy
-	}
-	return nil
+	return memory.record(describe("copy", src)+" to "+dest.patchLocation().String(), func() error {
+		return memory.set(dest, clonePatch(memory.at(src)))
+	})
 }
 
 // Formats a patch as sysex in NL3 format
@@ -398,12 +460,26 @@ func (memory *PatchMemory) export(ref patchRef) (*[]byte, error) {
 	return nil, errors.New("Requested location cannot be formatted as sysex.")
 }
 
+// Exports the patch at ref as though it were held at dest.
+func (memory *PatchMemory) exportAs(ref patchRef, dest patchRef, writer io.Writer) error {
+	patch, err := memory.get(ref)
+	if err != nil {
+		return err
+	}
+	sysex, err := toSysex(patch.(sysexable), dest)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(*sysex)
+	return err
+}
+
 // Accepts an array of patchLocations and writes out fully formatted sysex blocks
 func (memory *PatchMemory) exportLocations(refs []patchRef, writer io.Writer) error {
 	var exportdata []byte
 
 	for _, ref := range refs {
-		fdata, err := memory.export(patchRef{ref.patchType, MemoryT, ref.index})
+		fdata, err := memory.export(ref)
 		if err == ErrUninitialized {
 			continue // skip silently
 		} else if err != nil {
@@ -442,39 +518,102 @@ func (memory *PatchMemory) get(ref patchRef) (patch, error) {
 }
 
 func (memory *PatchMemory) importTo(s *sysex, ref patchRef, overwrite bool) error {
-	if ref.patchType != s.patchType() {
-		return ErrImportTypeMismatch
+	incoming, err := memory.patchFromSysex(s, ref)
+	if err == nil {
+		_, _, err = memory.setResolved(incoming, ref, overwritePolicy(overwrite))
 	}
+	return err
+}
 
-	switch s.patchType() {
-	case ProgramT:
-		return memory.importProgram(s, ref, overwrite)
-	case PerformanceT:
-		return memory.importPerformance(s, ref, overwrite)
+// Imports each NL3 message of the stream to the location it was dumped from or, if dest is given, to
+// consecutive locations starting at dest.
+func (memory *PatchMemory) importStream(input io.Reader, policy ConflictPolicy, dest *patchRef) (*ImportReport, error) {
+	report := new(ImportReport)
+	input, err := sysexStream(input)
+	if err != nil {
+		return report, err
 	}
 
-	return nil
+	var consumed int64
+	split := splitSysex(vendorNord, modelNL3)
+	scanner := bufio.NewScanner(input)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := split(data, atEOF)
+		consumed += int64(advance)
+		return advance, token, err
+	})
+
+	for scanner.Scan() {
+		entry := ImportEntry{Offset: consumed - int64(len(scanner.Bytes()))}
+		sysex, err := parseSysex(scanner.Bytes())
+		if sysex == nil {
+			report.add(entry, patchRef{}, false, err)
+			continue
+		}
+		entry.Name = sysex.printableName()
+		entry.PatchType = sysex.patchType()
+
+		ref := sysex.toPatchRef()
+		entry.Source = ref.patchLocation()
+		if dest != nil {
+			ref = *dest
+		}
+		var incoming patch
+		replaced := false
+		if err == nil {
+			incoming, err = memory.patchFromSysex(sysex, ref)
+		}
+		if err == nil {
+			ref, replaced, err = memory.setResolved(incoming, ref, policy)
+		}
+		report.add(entry, ref, replaced, err)
+		if err == ErrConflictAborted {
+			return report, err
+		}
+		if err == nil && dest != nil {
+			*dest = ref.increment()
+		}
+	}
+	return report, scanner.Err()
+}
+
+// Copies the patches into consecutive locations starting at dest. The whole range is checked
+// before anything is written, so either all of the patches are placed or none of them are.
+func (memory *PatchMemory) place(patches []patch, dest patchRef) error {
+	if !dest.valid() {
+		return ErrInvalidLocation
+	}
+	for i, patch := range patches {
+		currDest := patchRef{dest.patchType, dest.source, dest.index + i}
+
+		if !currDest.valid() {
+			return ErrMemoryOverflow
+		}
+		if patch.PatchType() != currDest.patchType {
+			return ErrXferTypeMismatch
+		}
+		if memory.initialized(currDest) {
+			return ErrMemoryOccupied
+		}
+	}
+
+	return memory.record(describe(fmt.Sprintf("place %d at", len(patches)), dest), func() error {
+		for i, patch := range patches {
+			memory.set(patchRef{dest.patchType, dest.source, dest.index + i}, clonePatch(patch))
+		}
+		return nil
+	})
 }
 
 // Force sets the location in ref to the patch pointer, cast appropriately.
 // Does not care if the location is already occupied (current contents will be lost if not previously copied to another location)
 // Returns an error if the patch and ref are not the same type.
 func (memory *PatchMemory) set(ref patchRef, patch patch) error {
-	err := ErrInvalidLocation
-
-	switch ref.patchType {
-	case PerformanceT:
-		if performancePtr, ok := patch.(*Performance); ok {
-			*memory.perfPtr(ref) = performancePtr
-			err = nil
-		}
-	case ProgramT:
-		if programPtr, ok := patch.(*Program); ok {
-			*memory.progPtr(ref) = programPtr
-			err = nil
-		}
+	if patch == nil || patch.PatchType() != ref.patchType {
+		return ErrInvalidLocation
 	}
-	return err
+	memory.put(ref, patch)
+	return nil
 }
 
 func (memory *PatchMemory) swap(src patchRef, dest patchRef) error {
@@ -488,122 +627,76 @@ func (memory *PatchMemory) swap(src patchRef, dest patchRef) error {
 		return ErrInvalidLocation
 	}
 
-	switch src.patchType {
-	case PerformanceT:
-		srcPtr := memory.perfPtr(src)
-		destPtr := memory.perfPtr(dest)
-		temp := *destPtr
-		*destPtr = *srcPtr
-		*srcPtr = temp
-	case ProgramT:
-		srcPtr := memory.progPtr(src)
-		destPtr := memory.progPtr(dest)
-		temp := *destPtr
-		*destPtr = *srcPtr
-		*srcPtr = temp
-	}
+	srcPatch, destPatch := memory.at(src), memory.at(dest)
+	memory.put(src, destPatch)
+	memory.put(dest, srcPatch)
 	return nil
 }
 
-// transfer can behave as a copy (mode is copyM) or a move (mode is moveM).
-// Returns an error if any of the len(src) locations following dest are not empty, or if src contains
-// patchLocations of different patchTypes
-func (memory *PatchMemory) transfer(src []patchRef, dest patchRef, mode transferMode) error {
-	var err error
-	var moved []patchRef
-
-	if len(src) == 0 {
-		return nil
-	}
-	if src[0].patchType != dest.patchType {
-		return ErrXferTypeMismatch
-	}
-
-	for i, currSrc := range src {
-		currDest := patchRef{dest.patchType, dest.source, dest.index + i}
-
-		if !currDest.valid() {
-			err = ErrMemoryOverflow
-		}
-		if currSrc.patchType != currDest.patchType {
-			err = ErrXferTypeMismatch
-		}
-		if memory.initialized(currDest) {
-			err = ErrMemoryOccupied
-		}
-		if err != nil {
-			memory.transfer(moved, src[0], moveM) // undo the ones moved so far
-			break
-		}
+// Copies (mode is copyM) or moves (mode is moveM) each patch in src to consecutive locations starting at
+// dest, resolving occupied destinations with the policy. If any patch cannot be placed, or the policy aborts, memory is left as it
+// was; an abort is reported as ErrMemoryOccupied. Blank sources are passed over.
+func (memory *PatchMemory) transfer(src []patchRef, dest patchRef, mode transferMode, policy ConflictPolicy) error {
+	return memory.atomically("transfer", func() error {
+		for i, currSrc := range src {
+			currDest := patchRef{dest.patchType, dest.source, dest.index + i}
 
-		// Handle move of each program type since their pointer values are separate
-		switch currSrc.patchType {
-		case PerformanceT:
-			srcPtr := memory.perfPtr(currSrc)
-			destPtr := memory.perfPtr(currDest)
+			if currSrc.patchType != currDest.patchType {
+				return ErrXferTypeMismatch
+			}
+			if !currDest.valid() {
+				return ErrMemoryOverflow
+			}
 
-			*destPtr = *srcPtr
-			if mode == moveM {
-				*srcPtr = nil
+			original, err := memory.get(currSrc)
+			if err != nil {
+				continue
+			}
+			incoming := original
+			if mode == copyM {
+				incoming = clonePatch(original)
 			}
-		case ProgramT:
-			srcPtr := memory.progPtr(currSrc)
-			destPtr := memory.progPtr(currDest)
 
-			*destPtr = *srcPtr
-			if mode == moveM {
-				*srcPtr = nil
+			placed, _, err := memory.setResolved(incoming, currDest, policy)
+			switch err {
+			case nil:
+			case ErrMemoryOccupied:
+				continue // skipped
+			case ErrConflictAborted:
+				return ErrMemoryOccupied
+			default:
+				return err
+			}
+			if mode == moveM && placed != currSrc {
+				memory.clear(currSrc)
 			}
 		}
-		moved = append(moved, currDest)
-	}
-
-	return err
+		return nil
+	})
 }
 
 // helpers
 
-func (memory *PatchMemory) importPerformance(s *sysex, dest patchRef, overwrite bool) error {
-	performanceData, err := newPerformanceFromBitstream(s.decodedBitstream)
-	if err == nil {
-		performance := Performance{
-			name:     s.nameAsArray(),
-			category: s.category(),
-			version:  s.version(),
-			data:     performanceData,
-		}
-
-		if existing, err := memory.get(dest); err == nil {
-			if overwrite {
-				fmt.Printf("Overwriting %s (%q) with %q\n", dest.String(), existing.PrintableName(), s.printableName())
-			} else {
-				return ErrMemoryOccupied
-			}
-		}
-		err = memory.set(dest, &performance)
+// Decodes the dump for loading into dest, applying the memory's range policy.
+func (memory *PatchMemory) patchFromSysex(s *sysex, dest patchRef) (patch, error) {
+	if dest.patchType != s.patchType() {
+		return nil, ErrImportTypeMismatch
 	}
-	return err
-}
 
-func (memory *PatchMemory) importProgram(s *sysex, dest patchRef, overwrite bool) error {
-	programData, err := newProgramFromBitstream(s.decodedBitstream)
-	if err == nil {
-		program := Program{
-			name:     s.nameAsArray(),
-			category: s.category(),
-			version:  s.version(),
-			data:     programData,
+	switch s.patchType() {
+	case PerformanceT:
+		performance, err := newPerformanceFromSysex(s)
+		if err != nil {
+			return nil, err
 		}
-		if existing, err := memory.get(dest); err == nil {
-			if overwrite {
-				fmt.Printf("Overwriting %s (%q) with %q\n", dest.String(), existing.PrintableName(), s.printableName())
-			} else {
-				return ErrMemoryOccupied
-			}
+		return performance, memory.enforceRanges(performance.data.Validate, performance.data.clamp)
+	default:
+		program, err := newProgramFromSysex(s)
+		if err != nil {
+			return nil, err
 		}
-		err = memory.set(dest, &program)
+		return program, memory.enforceRanges(program.data.Validate, program.data.clamp)
 	}
-	return err
 }
 
 func (memory *PatchMemory) initialized(ref patchRef) (result bool) {
@@ -631,6 +724,24 @@ func (memory *PatchMemory) initialized(ref patchRef) (result bool) {
 	return
 }
 
+func (memory *PatchMemory) initializedRefs(pt PatchType) []patchRef {
+	var result []patchRef
+	var size int
+
+	switch pt {
+	case PerformanceT:
+		size = len(memory.performances)
+	case ProgramT:
+		size = len(memory.programs)
+	}
+	for i := 0; i < size; i++ {
+		if ref := (patchRef{pt, MemoryT, i}); memory.initialized(ref) {
+			result = append(result, ref)
+		}
+	}
+	return result
+}
+
 func (memory *PatchMemory) numInitialized(pt PatchType, bank int) int {
 	var result int
 	offset := bank * BankSize
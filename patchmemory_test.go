@@ -335,6 +335,67 @@ func TestDeleteProgram(t *testing.T) {
 	}
 }
 
+func TestAuthors(t *testing.T) {
+	memory := populatedMemory(t, "ProgBank1.syx")
+	authors := memory.Authors(ProgramT)
+
+	if len(authors) == 0 {
+		t.Fatalf("Expected to find authors in ProgBank1.syx")
+	}
+	for _, author := range authors {
+		mls := memory.LocationsByAuthor(ProgramT, author)
+		if len(mls) == 0 {
+			t.Errorf("Author %q listed but no programs found", author)
+		}
+		for _, ml := range mls {
+			if p, _ := memory.GetProgram(ml); p.Author() != author {
+				t.Errorf("Filtering by %q returned %q", author, p.PrintableName())
+			}
+		}
+	}
+
+	sorted := memory.SortByAuthor(ProgramT)
+	if len(sorted) != memory.NumPrograms(true) {
+		t.Errorf("Expected %d sorted locations, got %d", memory.NumPrograms(true), len(sorted))
+	}
+	seenBlank := false
+	for i := 1; i < len(sorted); i++ {
+		prev, _ := memory.GetProgram(sorted[i-1])
+		curr, _ := memory.GetProgram(sorted[i])
+		seenBlank = seenBlank || prev.Author() == ""
+		if seenBlank && curr.Author() != "" {
+			t.Errorf("Program %q with author sorted after programs without one", curr.PrintableName())
+		}
+		if prev.Author() != "" && curr.Author() != "" && prev.Author() > curr.Author() {
+			t.Errorf("Programs out of author order: %q before %q", prev.PrintableName(), curr.PrintableName())
+		}
+	}
+}
+
+func TestSetAuthorInMemory(t *testing.T) {
+	memory := populatedMemory(t, "ProgBank1.syx")
+	mls := []MemoryLocation{{0, 42}, {0, 43}, {0, 44}}
+
+	if err := memory.SetAuthor(ProgramT, mls, "NL3"); err != nil {
+		t.Fatalf("Unexpected error re-stamping programs: %s", err)
+	}
+	for _, ml := range mls {
+		if p, _ := memory.GetProgram(ml); p.Author() != "NL3" {
+			t.Errorf("Expected %d:%d to be stamped, got %q", ml.Bank, ml.Location, p.PrintableName())
+		}
+	}
+
+	// An uninitialized location aborts the whole batch
+	before, _ := memory.GetProgram(mls[0])
+	beforeName := before.PrintableName()
+	if err := memory.SetAuthor(ProgramT, []MemoryLocation{mls[0], {1, 42}}, "XY"); err != ErrUninitialized {
+		t.Errorf("Expected ErrUninitialized, got %v", err)
+	}
+	if before.PrintableName() != beforeName {
+		t.Errorf("Failed batch still renamed %q to %q", beforeName, before.PrintableName())
+	}
+}
+
 // Helpers =======================================================
 
 func buildRefList(t *testing.T, memory *PatchMemory, pt PatchType, startBank, startLocation, numToMove int, permitBlank bool) (refs []patchRef) {
@@ -418,7 +479,7 @@ func expectSuccessfulTransfer(t *testing.T, memory *PatchMemory, src []patchRef,
 	dests := buildRefList(t, memory, dest.patchType, dest.bank(), dest.location(), len(src), true)
 	quicksummary := summarize(memory, src)
 
-	err := memory.transfer(src, dest, mode)
+	err := memory.transfer(src, dest, mode, ConflictAbort)
 	if err != nil {
 		t.Fatalf("Failure moving patches: %s", err)
 	}
@@ -488,7 +549,7 @@ func expectUnsuccessfulSwap(t *testing.T, memory *PatchMemory, aref patchRef, br
 
 func expectUnsuccessfulTransfer(t *testing.T, memory *PatchMemory, src []patchRef, dest patchRef, expectedError error, mode transferMode) {
 	quicksummary := summarize(memory, src)
-	err := memory.transfer(src, dest, mode)
+	err := memory.transfer(src, dest, mode, ConflictAbort)
 	if err != expectedError {
 		t.Errorf("Expected error %s, got: %s", expectedError, err)
 	}
@@ -73,3 +73,16 @@ func (ref *patchRef) String() string {
 
 	return fmt.Sprintf("%s in %s %s", typeStr, sourceStr, locationStr)
 }
+
+// The refs of every location in the bank.
+func bankRefs(pt PatchType, bank int) ([]patchRef, error) {
+	var refs []patchRef
+
+	if bank < 0 || !valid(pt, MemoryT, index(bank, 0)) {
+		return nil, ErrInvalidLocation
+	}
+	for location := 0; location < BankSize; location++ {
+		refs = append(refs, patchRef{pt, MemoryT, index(bank, location)})
+	}
+	return refs, nil
+}
@@ -12,8 +12,42 @@ type Performance struct {
 	data     *PerformanceData
 }
 
+// Marks the performance as saved by the previous known OS version. See knownVersions.
+func (performance *Performance) Downgrade() error {
+	if performance == nil {
+		return ErrUninitialized
+	}
+	to, err := adjacentVersion(performance.version, -1)
+	if err != nil {
+		return err
+	}
+	performance.setVersion(to)
+	return nil
+}
+
+// Marks the performance as saved by the newest known OS version. See knownVersions.
+// Performances which are already at least that new are left as they are.
+func (performance *Performance) Upgrade() error {
+	if performance == nil {
+		return ErrUninitialized
+	}
+	if to := knownVersions[len(knownVersions)-1]; versionX100(performance.version) < versionX100(to) {
+		performance.setVersion(to)
+	}
+	return nil
+}
+
 // Implement patch
 
+// Returns the author tag parsed from the end of the name, or "" if the name carries none.
+func (performance *Performance) Author() string {
+	if performance == nil {
+		return ""
+	}
+	_, author := splitAuthor(performance.name)
+	return author
+}
+
 func (performance *Performance) PatchType() PatchType {
 	return PerformanceT
 }
@@ -45,6 +79,18 @@ func (performance *Performance) SetCategory(int) error {
 	return ErrNoPerfCategory // performances don't support categories
 }
 
+// Re-stamps the name with the given author tag, keeping as much of the title as will fit.
+func (performance *Performance) SetAuthor(author string) error {
+	if performance == nil {
+		return ErrUninitialized
+	}
+	newName, err := stampAuthor(performance.Title(), author)
+	if err != nil {
+		return err
+	}
+	return performance.SetName(newName)
+}
+
 func (performance *Performance) SetName(newName string) error {
 	if performance == nil {
 		return ErrUninitialized
@@ -67,6 +113,15 @@ func (performance *Performance) Summary() string {
 	return fmt.Sprintf("%16.16q (%1.2f)", performance.PrintableName(), performance.version)
 }
 
+// Returns the name without the author tag.
+func (performance *Performance) Title() string {
+	if performance == nil {
+		return ""
+	}
+	title, _ := splitAuthor(performance.name)
+	return title
+}
+
 func (performance *Performance) Version() float64 {
 	return performance.version
 }
@@ -96,7 +151,10 @@ func (performance *Performance) sysexName() []byte {
 	return result
 }
 
-func (performance *Performance) sysexType() uint8 {
+func (performance *Performance) sysexType(source SourceType) uint8 {
+	if source == SlotT {
+		return performanceFromSlot
+	}
 	return performanceFromMemory
 }
 
@@ -104,3 +162,34 @@ func (performance *Performance) sysexVersion() []byte {
 	versionX100 := uint16(performance.version * 100)
 	return []byte{byte(versionX100 >> 8), byte(versionX100)}
 }
+
+// helpers
+
+// Copies the patch including its data, so that editing the copy leaves the original untouched.
+func (performance *Performance) clone() *Performance {
+	copy := *performance
+	data := *performance.data
+	copy.data = &data
+	return &copy
+}
+
+// Embedded programs carry no version number, so only the performance's changes.
+func (performance *Performance) setVersion(version float64) {
+	performance.data.Version_number = uint(versionX100(version))
+	performance.version = version
+}
+
+// Requires a valid sysex message containing a performance
+func newPerformanceFromSysex(s *sysex) (*Performance, error) {
+	performanceData, err := newPerformanceFromBitstream(s.decodedBitstream)
+	if err != nil {
+		return nil, err
+	}
+	performance := Performance{
+		name:     s.nameAsArray(),
+		category: s.category(),
+		version:  s.version(),
+		data:     performanceData,
+	}
+	return &performance, nil
+}
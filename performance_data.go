@@ -4,10 +4,13 @@ import (
 	"errors"
 )
 
-// Mask values such as Enabled_slots and Sustain_enable have 1 = slot 1, 2 = slot 2, 4 = slot 3, 8 = slot 4.
+// The names of the programs held in the slots of a performance.
+type PatchName [16]byte
+
+// Mask values such as Enabled_slots and Sustain_enable are SlotMasks. Slot gathers the fields of one slot.
 type PerformanceData struct {
 	Version_number       uint        `len:"16"`                  // Decimal OS version number (# x	100	)
-	Enabled_slots        uint        `len:"8" min:"0" max:"127"` // 0-15
+	Enabled_slots        SlotMask    `len:"8" min:"0" max:"127"` // 0-15
 	Focused_slot         uint        `len:"8" min:"0" max:"127"` // 0-3
 	Midi_channel_slot_a  uint        `len:"8" min:"0" max:"127"` // 0-16 0 = Off
 	Midi_channel_slot_b  uint        `len:"8" min:"0" max:"127"` // 0-16 0 = Off
@@ -20,9 +23,9 @@ type PerformanceData struct {
 	Splitpoint_key       uint        `len:"8" min:"0" max:"127"` // 0-127
 	Spare1               uint        `len:"7"`                   // Really just padding for the bool splitpoint_enable
 	Splitpoint_enable    bool        `len:"1"`                   // 0-1 Off or On
-	Sustain_enable       uint        `len:"8" min:"0" max:"127"` // 0-15 is a mask 0b00001111 for each of the four slots
-	Pitchbend_enable     uint        `len:"8" min:"0" max:"127"` // 0-15 is a mask 0b00001111 for each of the four slots
-	Modwheel_enable      uint        `len:"8" min:"0" max:"127"` // 0-15 is a mask 0b00001111 for each of the four slots
+	Sustain_enable       SlotMask    `len:"8" min:"0" max:"127"` // 0-15 is a mask 0b00001111 for each of the four slots
+	Pitchbend_enable     SlotMask    `len:"8" min:"0" max:"127"` // 0-15 is a mask 0b00001111 for each of the four slots
+	Modwheel_enable      SlotMask    `len:"8" min:"0" max:"127"` // 0-15 is a mask 0b00001111 for each of the four slots
 	Bank_slot_a          uint        `len:"8" min:"0" max:"7"`
 	Program_slot_a       uint        `len:"8" min:"0" max:"127"`
 	Bank_slot_b          uint        `len:"8" min:"0" max:"7"`
@@ -51,16 +54,18 @@ type PerformanceData struct {
 	Midi_clock_rate      uint        `len:"8" min:"0" max:"210"` // 0-210
 	Bend_range_up        uint        `len:"8" min:"0" max:"24"`  // 0-24
 	Bend_range_down      uint        `len:"8" min:"0" max:"24"`  // 0-24
-	Patchname_slot_a     [16]byte    `len:"8"`                   // Offset 42
-	Patchname_slot_b     [16]byte    `len:"8"`
-	Patchname_slot_c     [16]byte    `len:"8"`
-	Patchname_slot_d     [16]byte    `len:"8"`
+	Patchname_slot_a     PatchName   `len:"8"`                   // Offset 42
+	Patchname_slot_b     PatchName   `len:"8"`
+	Patchname_slot_c     PatchName   `len:"8"`
+	Patchname_slot_d     PatchName   `len:"8"`
 	Patch_data_a         ProgramData `len:"1498"`
+	Patch_pad_a          uint        `len:"6"` // Each slot program is padded to 188 bytes; these are the bits once read as Spare16 and Checksum
 	Patch_data_b         ProgramData `len:"1498"`
+	Patch_pad_b          uint        `len:"6"`
 	Patch_data_c         ProgramData `len:"1498"`
+	Patch_pad_c          uint        `len:"6"`
 	Patch_data_d         ProgramData `len:"1498"`
-	Spare16              uint        `len:"16"` // This is nuts, there's data there.
-	Checksum             uint        `len:"8"`
+	Patch_pad_d          uint        `len:"6"`
 }
 
 func (performanceData *PerformanceData) dumpSysex() (*[]byte, error) {
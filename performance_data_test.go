@@ -31,3 +31,16 @@ func TestDumpPerformanceSysex(t *testing.T) {
 	decodedOS := unpackSysex(*outputSysex)
 	binaryExpectEqual(t, &decodedPS, &decodedOS)
 }
+
+// Slots B to D only decode in range if every slot program is padded as the unit pads it.
+func TestSlotProgramsAligned(t *testing.T) {
+	performances := populatedMemory(t, "AllPerformances.syx")
+	for _, performance := range performances.performances {
+		if performance == nil {
+			continue
+		}
+		if errs := performance.data.Validate(); errs != nil {
+			t.Errorf("%q: %v", performance.PrintableName(), errs)
+		}
+	}
+}
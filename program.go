@@ -16,6 +16,27 @@ func (program *Program) Category() int {
 	return int(program.category)
 }
 
+// Marks the program as saved by the previous known OS version. See knownVersions.
+func (program *Program) Downgrade() error {
+	if program == nil {
+		return ErrUninitialized
+	}
+	to, err := adjacentVersion(program.version, -1)
+	if err != nil {
+		return err
+	}
+	program.setVersion(to)
+	return nil
+}
+
+// Describes filter 1 as its type followed by its slope, e.g. "Lowpass 24dB".
+func (program *Program) Filter1() string {
+	if program == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s %s", program.data.Filt1_type, program.data.Filt1_slope)
+}
+
 func (program *Program) PrintableContents() string {
 	if program == nil {
 		return strUninitializedName
@@ -25,8 +46,83 @@ func (program *Program) PrintableContents() string {
 	return writer.String()
 }
 
+// The switch setters reject values the unit does not define.
+
+func (program *Program) SetArpeggioMode(mode ArpeggioMode) error {
+	return program.setSwitch(mode.Valid(), func(data *ProgramData) { data.Arpeggio_mode = mode })
+}
+
+func (program *Program) SetFilt1Type(filterType FilterType) error {
+	return program.setSwitch(filterType.Valid(), func(data *ProgramData) { data.Filt1_type = filterType })
+}
+
+func (program *Program) SetFilt1Slope(slope FilterSlope) error {
+	return program.setSwitch(slope.Valid(), func(data *ProgramData) { data.Filt1_slope = slope })
+}
+
+func (program *Program) SetFilt2Type(filterType FilterType) error {
+	return program.setSwitch(filterType.Valid(), func(data *ProgramData) { data.Filt2_type = filterType })
+}
+
+func (program *Program) SetLfo1Destination(destination ModDestination) error {
+	return program.setSwitch(destination.Valid(), func(data *ProgramData) { data.Lfo1_destination = destination })
+}
+
+func (program *Program) SetLfo1Waveform(waveform LfoWaveform) error {
+	return program.setSwitch(waveform.Valid(), func(data *ProgramData) { data.Lfo1_waveform = waveform })
+}
+
+func (program *Program) SetLfo2Destination(destination ModDestination) error {
+	return program.setSwitch(destination.Valid(), func(data *ProgramData) { data.Lfo2_destination = destination })
+}
+
+func (program *Program) SetLfo2Waveform(waveform LfoWaveform) error {
+	return program.setSwitch(waveform.Valid(), func(data *ProgramData) { data.Lfo2_waveform = waveform })
+}
+
+func (program *Program) SetModEnvDestination(destination ModDestination) error {
+	return program.setSwitch(destination.Valid(), func(data *ProgramData) { data.Mod_env_destination = destination })
+}
+
+func (program *Program) SetMonoAllocationMode(allocation MonoAllocation) error {
+	return program.setSwitch(allocation.Valid(), func(data *ProgramData) { data.Mono_allocation_mode = allocation })
+}
+
+func (program *Program) SetOsc1Waveform(waveform Waveform) error {
+	return program.setSwitch(waveform.Valid(), func(data *ProgramData) { data.Osc1_waveform = waveform })
+}
+
+func (program *Program) SetOsc2Waveform(waveform Waveform) error {
+	return program.setSwitch(waveform.Valid(), func(data *ProgramData) { data.Osc2_waveform = waveform })
+}
+
+func (program *Program) SetOscmodType(modType OscModType) error {
+	return program.setSwitch(modType.Valid(), func(data *ProgramData) { data.Oscmod_type = modType })
+}
+
+// Marks the program as saved by the newest known OS version. See knownVersions.
+// Programs which are already at least that new are left as they are.
+func (program *Program) Upgrade() error {
+	if program == nil {
+		return ErrUninitialized
+	}
+	if to := knownVersions[len(knownVersions)-1]; versionX100(program.version) < versionX100(to) {
+		program.setVersion(to)
+	}
+	return nil
+}
+
 // Implement patch
 
+// Returns the author tag parsed from the end of the name, or "" if the name carries none.
+func (program *Program) Author() string {
+	if program == nil {
+		return ""
+	}
+	_, author := splitAuthor(program.name)
+	return author
+}
+
 func (program *Program) PatchType() PatchType {
 	return ProgramT
 }
@@ -44,10 +140,7 @@ func (program *Program) PrintableCategory() string {
 	if program == nil {
 		return ""
 	}
-	if program.category >= uint8(len(Categories)) {
-		return fmt.Sprintf("Unknown: %02x", program.category)
-	}
-	return Categories[program.category]
+	return categoryName(program.category)
 }
 
 func (program *Program) PrintableName() string {
@@ -68,6 +161,18 @@ func (program *Program) SetCategory(newCategory int) error {
 	return nil
 }
 
+// Re-stamps the name with the given author tag, keeping as much of the title as will fit.
+func (program *Program) SetAuthor(author string) error {
+	if program == nil {
+		return ErrUninitialized
+	}
+	newName, err := stampAuthor(program.Title(), author)
+	if err != nil {
+		return err
+	}
+	return program.SetName(newName)
+}
+
 func (program *Program) SetName(newName string) error {
 	if program == nil {
 		return ErrUninitialized // can't set a category on an uninitialized program
@@ -90,6 +195,15 @@ func (program *Program) Summary() string {
 	return fmt.Sprintf("%+-16.16q : %8s (%1.2f)", program.PrintableName(), program.PrintableCategory(), program.version)
 }
 
+// Returns the name without the author tag.
+func (program *Program) Title() string {
+	if program == nil {
+		return ""
+	}
+	title, _ := splitAuthor(program.name)
+	return title
+}
+
 func (program *Program) Version() float64 {
 	return program.version
 }
@@ -119,7 +233,10 @@ func (program *Program) sysexName() []byte {
 	return result
 }
 
-func (program *Program) sysexType() uint8 {
+func (program *Program) sysexType(source SourceType) uint8 {
+	if source == SlotT {
+		return programFromSlot
+	}
 	return programFromMemory
 }
 
@@ -127,3 +244,44 @@ func (program *Program) sysexVersion() []byte {
 	versionX100 := uint16(program.version * 100)
 	return []byte{byte(versionX100 >> 8), byte(versionX100)}
 }
+
+// helpers
+
+// Copies the patch including its data, so that editing the copy leaves the original untouched.
+func (program *Program) clone() *Program {
+	copy := *program
+	data := *program.data
+	copy.data = &data
+	return &copy
+}
+
+func (program *Program) setSwitch(valid bool, set func(data *ProgramData)) error {
+	if program == nil {
+		return ErrUninitialized
+	}
+	if !valid {
+		return ErrInvalidValue
+	}
+	set(program.data)
+	return nil
+}
+
+func (program *Program) setVersion(version float64) {
+	program.data.Version_number = uint(versionX100(version))
+	program.version = version
+}
+
+// Requires a valid sysex message containing a program
+func newProgramFromSysex(s *sysex) (*Program, error) {
+	programData, err := newProgramFromBitstream(s.decodedBitstream)
+	if err != nil {
+		return nil, err
+	}
+	program := Program{
+		name:     s.nameAsArray(),
+		category: s.category(),
+		version:  s.version(),
+		data:     programData,
+	}
+	return &program, nil
+}
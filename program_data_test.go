@@ -40,3 +40,23 @@ func TestPackAndUnpackSysex(t *testing.T) {
 		t.Errorf("Pack and Unpack not symmetric: %x / %x", tailBytes(bitsToRepack, 8), tailBytes(repackedBits, 8))
 	}
 }
+
+func TestSignedFieldsDecode(t *testing.T) {
+	negativeMorphs := 0
+
+	programs := populatedMemory(t, "AllFactoryPrograms1.20RevA.syx")
+	for _, program := range programs.programs {
+		if program == nil {
+			continue
+		}
+		if errs := program.data.Validate(); errs != nil {
+			t.Errorf("%q: %v", program.PrintableName(), errs)
+		}
+		if program.data.Wheel_morph_params.Filt_frequency1 < 0 {
+			negativeMorphs++
+		}
+	}
+	if negativeMorphs == 0 {
+		t.Errorf("Expected some factory programs to close the filter with the wheel")
+	}
+}
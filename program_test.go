@@ -70,6 +70,58 @@ func TestSetName(t *testing.T) {
 	}
 }
 
+func TestAuthorAndTitle(t *testing.T) {
+	cases := []struct{ name, title, author string }{
+		{"Blade run    ZON", "Blade run", "ZON"},
+		{"Orchestra     HN", "Orchestra", "HN"},
+		{"BasicBass1   DLX", "BasicBass1", "DLX"},
+		{"Elektro        -", "Elektro        -", ""},
+		{"Lead Pad", "Lead Pad", ""},
+		{"PAD", "PAD", ""},
+		{"FunkyBrass   TB ", "FunkyBrass", "TB"},
+		{"Saw Lead      V2", "Saw Lead", "V2"},
+		{"Saw Lead V2", "Saw Lead V2", ""},
+		{"Bass FM", "Bass FM", ""},
+		{"Organ B3", "Organ B3", ""},
+	}
+
+	for _, c := range cases {
+		var name [16]byte
+		copy(name[:], c.name)
+		p := Program{name: name}
+		if p.Title() != c.title || p.Author() != c.author {
+			t.Errorf("Splitting %q: expected (%q, %q), got (%q, %q)", c.name, c.title, c.author, p.Title(), p.Author())
+		}
+	}
+}
+
+func TestSetAuthor(t *testing.T) {
+	var name [16]byte
+	copy(name[:], "A Very Long Title")
+	p := Program{name: name}
+
+	if err := p.SetAuthor("ZON"); err != nil {
+		t.Fatalf("Unexpected error setting author: %s", err)
+	}
+	if p.PrintableName() != "A Very Long  ZON" {
+		t.Errorf("Expected name %q, got %q", "A Very Long  ZON", p.PrintableName())
+	}
+
+	// Re-stamping replaces the existing author rather than stacking them
+	if err := p.SetAuthor("HN"); err != nil {
+		t.Fatalf("Unexpected error setting author: %s", err)
+	}
+	if p.Title() != "A Very Long" || p.Author() != "HN" {
+		t.Errorf("Expected (%q, %q), got (%q, %q)", "A Very Long", "HN", p.Title(), p.Author())
+	}
+
+	for _, invalid := range []string{"", "zon", "TWO TOKENS", "FIFTEENCHARSXXX"} {
+		if err := p.SetAuthor(invalid); err != ErrInvalidAuthor {
+			t.Errorf("Expected ErrInvalidAuthor setting author %q, got %v", invalid, err)
+		}
+	}
+}
+
 func TestPrintableName(t *testing.T) {
 
 }
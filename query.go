@@ -0,0 +1,207 @@
+package nordlead3
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Selects programs for FindPrograms. Fields left zero match every program; the others must all match.
+type ProgramQuery struct {
+	Name        string  // found anywhere in the name, ignoring case
+	NamePattern string  // a regular expression the name must match
+	Category    string  // e.g. "Pad", as in Categories
+	Author      string  // the author tag, e.g. "ZON"
+	Version     float64 // e.g. 1.18
+	Where       string  // parameter predicates, e.g. "Arpeggio_run == true && Osc1_waveform == Sine"
+}
+
+// Selects performances for FindPerformances, as ProgramQuery does programs. Predicates may reach into the
+// slots, e.g. "Patch_data_b.Arpeggio_run == true".
+type PerformanceQuery struct {
+	Name        string
+	NamePattern string
+	Author      string
+	Version     float64
+	SlotProgram string // found anywhere in the name of the program in any of the four slots, ignoring case
+	Where       string
+}
+
+// A comparison of a parameter with a value, such as "Filt_frequency1 >= 64".
+type predicate struct {
+	path  string
+	op    string
+	value int
+}
+
+// Longer operators come first so that "<=" is not taken for "<".
+var predicateOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// Returns the locations of the performances matching the query, in memory order.
+func (memory *PatchMemory) FindPerformances(query PerformanceQuery) ([]MemoryLocation, error) {
+	match, err := newPatchMatcher(query.Name, query.NamePattern, query.Author, query.Version, query.Where, reflect.TypeOf(PerformanceData{}))
+	if err != nil {
+		return nil, err
+	}
+	slotProgram := strings.ToLower(query.SlotProgram)
+
+	return memory.find(PerformanceT, func(p patch) bool {
+		performance := p.(*Performance)
+		if !match(p, reflect.ValueOf(performance.data).Elem()) {
+			return false
+		}
+		if slotProgram == "" {
+			return true
+		}
+		for _, name := range performance.slotNames() {
+			if strings.Contains(strings.ToLower(nameToString([16]byte(name))), slotProgram) {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+// Returns the locations of the programs matching the query, in memory order.
+func (memory *PatchMemory) FindPrograms(query ProgramQuery) ([]MemoryLocation, error) {
+	match, err := newPatchMatcher(query.Name, query.NamePattern, query.Author, query.Version, query.Where, reflect.TypeOf(ProgramData{}))
+	if err != nil {
+		return nil, err
+	}
+	category := -1
+	if query.Category != "" {
+		parsed, err := categoryFromString(query.Category)
+		if err != nil {
+			return nil, err
+		}
+		category = int(parsed)
+	}
+
+	return memory.find(ProgramT, func(p patch) bool {
+		program := p.(*Program)
+		return match(p, reflect.ValueOf(program.data).Elem()) && (category < 0 || program.Category() == category)
+	}), nil
+}
+
+// helpers
+
+func (memory *PatchMemory) find(pt PatchType, match func(p patch) bool) []MemoryLocation {
+	var result []MemoryLocation
+
+	for _, ref := range memory.initializedRefs(pt) {
+		if match(memory.at(ref)) {
+			result = append(result, MemoryLocation{ref.bank(), ref.location()})
+		}
+	}
+	return result
+}
+
+// Builds a test of the criteria common to programs and performances. The predicates are checked against
+// the type of the patch data.
+func newPatchMatcher(name, pattern, author string, version float64, where string, dataType reflect.Type) (func(p patch, data reflect.Value) bool, error) {
+	var re *regexp.Regexp
+	if pattern != "" {
+		var err error
+		if re, err = regexp.Compile(pattern); err != nil {
+			return nil, err
+		}
+	}
+	predicates, err := parsePredicates(dataType, where)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.ToLower(name)
+
+	return func(p patch, data reflect.Value) bool {
+		switch {
+		case name != "" && !strings.Contains(strings.ToLower(p.PrintableName()), name):
+		case re != nil && !re.MatchString(strings.TrimRight(p.PrintableName(), " ")):
+		case author != "" && p.Author() != author:
+		case version != 0 && versionX100(p.Version()) != versionX100(version):
+		default:
+			for _, predicate := range predicates {
+				if !predicate.matches(data) {
+					return false
+				}
+			}
+			return true
+		}
+		return false
+	}, nil
+}
+
+// Parses predicates joined by "&&". Values are numbers, true or false for switches, or enum names.
+func parsePredicates(dataType reflect.Type, where string) ([]predicate, error) {
+	var result []predicate
+	if strings.TrimSpace(where) == "" {
+		return nil, nil
+	}
+
+	for _, clause := range strings.Split(where, "&&") {
+		predicate, err := parsePredicate(dataType, strings.TrimSpace(clause))
+		if err != nil {
+			return nil, fmt.Errorf("%q: %v", clause, err)
+		}
+		result = append(result, predicate)
+	}
+	return result, nil
+}
+
+func parsePredicate(dataType reflect.Type, clause string) (predicate, error) {
+	for _, op := range predicateOps {
+		at := strings.Index(clause, op)
+		if at < 0 {
+			continue
+		}
+		path := strings.TrimSpace(clause[:at])
+		text := strings.TrimSpace(clause[at+len(op):])
+
+		rv := reflect.New(dataType).Elem()
+		if _, err := getParameter(rv, path); err != nil {
+			return predicate{}, err
+		}
+		var value int
+		var err error
+		switch text {
+		case "true":
+			value = 1
+		case "false":
+			value = 0
+		default:
+			value, err = parseSheetValue(rv, path, text)
+		}
+		return predicate{path, op, value}, err
+	}
+	return predicate{}, errors.New(`expected a comparison such as "Osc1_waveform == Sine"`)
+}
+
+func (p predicate) matches(data reflect.Value) bool {
+	value, err := getParameter(data, p.path)
+	if err != nil {
+		return false
+	}
+	switch p.op {
+	case "==":
+		return value == p.value
+	case "!=":
+		return value != p.value
+	case "<":
+		return value < p.value
+	case "<=":
+		return value <= p.value
+	case ">":
+		return value > p.value
+	default:
+		return value >= p.value
+	}
+}
+
+func (performance *Performance) slotNames() []PatchName {
+	var result []PatchName
+	for slot := 0; slot < NumSlots; slot++ {
+		result = append(result, *performance.data.Slot(slot).Name)
+	}
+	return result
+}
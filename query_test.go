@@ -0,0 +1,109 @@
+package nordlead3
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindProgramsByHeader(t *testing.T) {
+	memory := populatedMemory(t, "Program-BladeRun     ZON-1.18.syx")
+	memory.Import(bytes.NewReader(helperLoadBytes(t, "Program-Elektro         -1.20.syx")), ConflictNextFree)
+	elektro := memory.LocationsByAuthor(ProgramT, "PG")
+	blade := []MemoryLocation{{validProgramBank, validProgramLocation}}
+
+	cases := []struct {
+		query    ProgramQuery
+		expected []MemoryLocation
+	}{
+		{ProgramQuery{Name: "BLADE"}, blade},
+		{ProgramQuery{NamePattern: "^Blade run +ZON$"}, blade},
+		{ProgramQuery{Category: "pad"}, blade},
+		{ProgramQuery{Author: "PG"}, elektro},
+		{ProgramQuery{Version: 1.2}, elektro},
+		{ProgramQuery{Name: "e", Version: 1.18}, blade},
+		{ProgramQuery{Name: "Nothing like it"}, nil},
+	}
+	for _, c := range cases {
+		if found, err := memory.FindPrograms(c.query); err != nil || !reflect.DeepEqual(found, c.expected) {
+			t.Errorf("%+v: expected %v, got %v (%v)", c.query, c.expected, found, err)
+		}
+	}
+}
+
+func TestFindProgramsWhere(t *testing.T) {
+	memory := populatedMemory(t, "AllPrograms.syx")
+
+	cases := []struct {
+		where string
+		match func(p *Program) bool
+	}{
+		{"Arpeggio_run == true && Osc1_waveform == Sine", func(p *Program) bool {
+			return p.data.Arpeggio_run && p.data.Osc1_waveform == WaveformSine
+		}},
+		{"Filt_frequency1 >= 100", func(p *Program) bool { return p.data.Filt_frequency1 >= 100 }},
+		{"Filt1_type != Lowpass && Wheel_morph_params.Filt_frequency1 < 0", func(p *Program) bool {
+			return p.data.Filt1_type != FilterLowpass && p.data.Wheel_morph_params.Filt_frequency1 < 0
+		}},
+	}
+	for _, c := range cases {
+		var expected []MemoryLocation
+		for _, ref := range memory.initializedRefs(ProgramT) {
+			if c.match(memory.at(ref).(*Program)) {
+				expected = append(expected, MemoryLocation{ref.bank(), ref.location()})
+			}
+		}
+		if found, err := memory.FindPrograms(ProgramQuery{Where: c.where}); err != nil || !reflect.DeepEqual(found, expected) {
+			t.Errorf("%q: expected %v, got %v (%v)", c.where, expected, found, err)
+		}
+	}
+}
+
+func TestFindRejectsBadQueries(t *testing.T) {
+	memory := new(PatchMemory)
+
+	for _, query := range []ProgramQuery{
+		{NamePattern: "(unclosed"},
+		{Category: "Kazoo"},
+		{Where: "Filt_frequency3 == 1"},
+		{Where: "Osc1_waveform == Kazoo"},
+		{Where: "Osc1_waveform is Sine"},
+		{Where: "Mono_mode == true &&"},
+	} {
+		if _, err := memory.FindPrograms(query); err == nil {
+			t.Errorf("%+v: expected an error", query)
+		}
+	}
+}
+
+func TestFindPerformances(t *testing.T) {
+	memory := populatedMemory(t, "AllPerformances.syx")
+	first := memory.at(memory.initializedRefs(PerformanceT)[0]).(*Performance)
+	slotName := strings.TrimSpace(nameToString([16]byte(first.data.Patchname_slot_c)))
+
+	var expected []MemoryLocation
+	for _, ref := range memory.initializedRefs(PerformanceT) {
+		performance := memory.at(ref).(*Performance)
+		for _, name := range performance.slotNames() {
+			if strings.Contains(strings.ToLower(nameToString([16]byte(name))), strings.ToLower(slotName)) {
+				expected = append(expected, MemoryLocation{ref.bank(), ref.location()})
+				break
+			}
+		}
+	}
+	found, err := memory.FindPerformances(PerformanceQuery{SlotProgram: slotName})
+	if err != nil || len(found) == 0 || !reflect.DeepEqual(found, expected) {
+		t.Errorf("Searching for slot program %q: expected %v, got %v (%v)", slotName, expected, found, err)
+	}
+
+	expected = nil
+	for _, ref := range memory.initializedRefs(PerformanceT) {
+		if memory.at(ref).(*Performance).data.Patch_data_b.Arpeggio_run {
+			expected = append(expected, MemoryLocation{ref.bank(), ref.location()})
+		}
+	}
+	if found, err := memory.FindPerformances(PerformanceQuery{Where: "Patch_data_b.Arpeggio_run == true"}); err != nil || !reflect.DeepEqual(found, expected) {
+		t.Errorf("Expected %v, got %v (%v)", expected, found, err)
+	}
+}
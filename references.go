@@ -0,0 +1,208 @@
+package nordlead3
+
+import (
+	"fmt"
+)
+
+// Why a performance slot's reference no longer leads to the program the slot holds.
+type StaleReason int
+
+const (
+	RefBlank   StaleReason = iota // the referenced location is blank
+	RefRenamed                    // the program there has another name
+	RefEdited                     // the program there has the same name but different parameters
+)
+
+// A performance slot whose bank and program numbers do not lead to a copy of the program it holds.
+type StaleReference struct {
+	Performance MemoryLocation
+	Slot        int            // 0 to 3 for A to D
+	Program     MemoryLocation // where the slot refers to
+	Reason      StaleReason
+}
+
+func (reason StaleReason) String() string {
+	switch reason {
+	case RefBlank:
+		return "blank"
+	case RefRenamed:
+		return "renamed"
+	case RefEdited:
+		return "edited"
+	default:
+		return fmt.Sprintf("StaleReason(%d)", int(reason))
+	}
+}
+
+func (stale StaleReference) String() string {
+	return fmt.Sprintf("%d:%03d slot %c -> %d:%03d (%s)", stale.Performance.Bank, stale.Performance.Location,
+		'A'+stale.Slot, stale.Program.Bank, stale.Program.Location, stale.Reason)
+}
+
+// Points each stale reference at the one program in memory matching the slot in both name and content.
+// References matching no program, or several, are left alone. Returns the number repaired. This is a
+// single operation for Undo.
+func (memory *PatchMemory) RepairReferences() int {
+	repairs := make(map[patchRef]map[int]MemoryLocation)
+	repaired := 0
+
+	for _, ref := range memory.initializedRefs(PerformanceT) {
+		performance := memory.at(ref).(*Performance)
+		for _, stale := range memory.staleSlots(performance) {
+			if ml, ok := memory.findSlotProgram(performance, stale.Slot); ok {
+				if repairs[ref] == nil {
+					repairs[ref] = make(map[int]MemoryLocation)
+				}
+				repairs[ref][stale.Slot] = ml
+				repaired++
+			}
+		}
+	}
+	if repaired == 0 {
+		return 0
+	}
+
+	memory.record(fmt.Sprintf("repair %d references", repaired), func() error {
+		for ref, slots := range repairs {
+			memory.put(ref, repointed(memory.at(ref).(*Performance), slots))
+		}
+		return nil
+	})
+	return repaired
+}
+
+// Rewrites the bank and program numbers of performance slots to follow the programs when MovePrograms or
+// SwapPrograms moves them. It is off until turned on.
+func (memory *PatchMemory) SetReferenceTracking(on bool) {
+	memory.trackReferences = on
+}
+
+// Lists the enabled performance slots whose references do not lead to the program the slot holds, in memory
+// order. Programs are compared at the performance's OS version.
+func (memory *PatchMemory) StaleReferences() []StaleReference {
+	var result []StaleReference
+
+	for _, ref := range memory.initializedRefs(PerformanceT) {
+		for _, stale := range memory.staleSlots(memory.at(ref).(*Performance)) {
+			stale.Performance = MemoryLocation{ref.bank(), ref.location()}
+			result = append(result, stale)
+		}
+	}
+	return result
+}
+
+// helpers
+
+// Runs action, which rearranges programs, and then, if tracking is on, points the performance slots that
+// referred to a program at wherever it went. Programs are followed by identity, so action must move them
+// rather than copy them. A program which action removes is followed to wherever the program replacing it
+// in replacements went, if any.
+func (memory *PatchMemory) followPrograms(replacements map[*Program]*Program, action func() error) error {
+	if !memory.trackReferences {
+		return action()
+	}
+
+	was := make(map[*Program]int)
+	for i, program := range memory.programs {
+		if program != nil {
+			was[program] = i
+		}
+	}
+	if err := action(); err != nil {
+		return err
+	}
+	now := make(map[*Program]int)
+	for i, program := range memory.programs {
+		if program != nil {
+			now[program] = i
+		}
+	}
+	moved := make(map[int]int)
+	for program, from := range was {
+		to, ok := now[program]
+		if !ok {
+			to, ok = now[replacements[program]]
+		}
+		if ok && from != to {
+			moved[from] = to
+		}
+	}
+	if len(moved) == 0 {
+		return nil
+	}
+
+	for _, ref := range memory.initializedRefs(PerformanceT) {
+		performance := memory.at(ref).(*Performance)
+		slots := make(map[int]MemoryLocation)
+		for slot := 0; slot < NumSlots; slot++ {
+			if to, ok := moved[performance.data.Slot(slot).Location().index()]; ok {
+				slots[slot] = MemoryLocation{bank(to), location(to)}
+			}
+		}
+		if len(slots) > 0 {
+			memory.put(ref, repointed(performance, slots))
+		}
+	}
+	return nil
+}
+
+// Returns a copy of the performance with the slots referring to the given locations.
+func repointed(performance *Performance, slots map[int]MemoryLocation) *Performance {
+	result := performance.clone()
+	for slot, ml := range slots {
+		result.data.Slot(slot).SetLocation(ml)
+	}
+	return result
+}
+
+// Checks the enabled slots of the performance against the programs they refer to. The results do not say
+// where the performance is.
+func (memory *PatchMemory) staleSlots(performance *Performance) []StaleReference {
+	var result []StaleReference
+
+	for slot := 0; slot < NumSlots; slot++ {
+		view := performance.data.Slot(slot)
+		if !view.Enabled() {
+			continue
+		}
+		ml := view.Location()
+
+		program, _ := memory.at(patchRef{ProgramT, MemoryT, ml.index()}).(*Program)
+		reason := RefBlank
+		switch {
+		case program == nil:
+		case PatchName(program.name) != *view.Name:
+			reason = RefRenamed
+		case !slotHolds(performance, slot, program):
+			reason = RefEdited
+		default:
+			continue
+		}
+		result = append(result, StaleReference{Slot: slot, Program: ml, Reason: reason})
+	}
+	return result
+}
+
+// Looks for the one program in memory that the slot holds a copy of.
+func (memory *PatchMemory) findSlotProgram(performance *Performance, slot int) (MemoryLocation, bool) {
+	var found []MemoryLocation
+	name := *performance.data.Slot(slot).Name
+
+	for _, ref := range memory.initializedRefs(ProgramT) {
+		program := memory.at(ref).(*Program)
+		if PatchName(program.name) == name && slotHolds(performance, slot, program) {
+			found = append(found, MemoryLocation{ref.bank(), ref.location()})
+		}
+	}
+	if len(found) != 1 {
+		return MemoryLocation{}, false
+	}
+	return found[0], true
+}
+
+// Reports whether the slot holds a copy of the program's parameters, as setSlotProgram would put them there.
+func slotHolds(performance *Performance, slot int, program *Program) bool {
+	scratch := &Performance{version: performance.version, data: new(PerformanceData)}
+	scratch.setSlotProgram(slot, program, MemoryLocation{})
+	return *performance.data.Slot(slot).Data == *scratch.data.Slot(slot).Data
+}
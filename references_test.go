@@ -0,0 +1,88 @@
+package nordlead3
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStaleReferences(t *testing.T) {
+	memory, perf := referencesMemory(t)
+	blade := MemoryLocation{validProgramBank, validProgramLocation}
+	if stale := memory.StaleReferences(); stale != nil {
+		t.Fatalf("Expected a freshly composed performance to have no stale references, got %v", stale)
+	}
+
+	memory.SetName(ProgramT, blade, "Renamed")
+	value, _ := memory.programs[0].Get("Filt_resonance")
+	edited := memory.programs[0].clone()
+	edited.Set("Filt_resonance", value^1)
+	memory.set(patchRef{ProgramT, MemoryT, 0}, edited)
+
+	expected := []StaleReference{
+		{perf, 0, blade, RefRenamed},
+		{perf, 1, MemoryLocation{0, 0}, RefEdited},
+	}
+	if stale := memory.StaleReferences(); !reflect.DeepEqual(stale, expected) {
+		t.Errorf("Expected %v, got %v", expected, stale)
+	}
+
+	memory.DeleteProgram(blade)
+	if stale := memory.StaleReferences(); len(stale) != 2 || stale[0].Reason != RefBlank || stale[0].String() != "0:000 slot A -> 3:004 (blank)" {
+		t.Errorf("Expected slot A to refer to a blank location, got %v", stale)
+	}
+}
+
+func TestRepairReferences(t *testing.T) {
+	memory, _ := referencesMemory(t)
+	memory.MovePrograms([]MemoryLocation{{validProgramBank, validProgramLocation}}, MemoryLocation{5, 0}, nil)
+	if stale := memory.StaleReferences(); len(stale) != 1 || stale[0].Reason != RefBlank {
+		t.Fatalf("Expected moving the program to leave slot A stale, got %v", stale)
+	}
+
+	if repaired := memory.RepairReferences(); repaired != 1 || memory.StaleReferences() != nil {
+		t.Errorf("Expected slot A to be repointed at the moved program, repaired %d", repaired)
+	}
+	if description, _ := memory.Undo(); description != "repair 1 references" || len(memory.StaleReferences()) != 1 {
+		t.Errorf("Expected Undo to put the stale reference back, got %q", description)
+	}
+
+	memory.copy(patchRef{ProgramT, MemoryT, index(5, 0)}, patchRef{ProgramT, MemoryT, index(5, 1)})
+	if repaired := memory.RepairReferences(); repaired != 0 {
+		t.Errorf("Expected a reference matching two programs to be left alone")
+	}
+}
+
+func TestReferenceTracking(t *testing.T) {
+	memory, perf := referencesMemory(t)
+	memory.SetReferenceTracking(true)
+
+	memory.MovePrograms([]MemoryLocation{{validProgramBank, validProgramLocation}}, MemoryLocation{5, 0}, nil)
+	memory.SwapPrograms(MemoryLocation{0, 0}, MemoryLocation{0, 9})
+	performance, _ := memory.GetPerformance(perf)
+	data := performance.data
+	if data.Bank_slot_a != 5 || data.Program_slot_a != 0 || data.Bank_slot_b != 0 || data.Program_slot_b != 9 {
+		t.Errorf("Expected the references to follow the programs, got %d:%d and %d:%d", data.Bank_slot_a, data.Program_slot_a, data.Bank_slot_b, data.Program_slot_b)
+	}
+	if stale := memory.StaleReferences(); stale != nil {
+		t.Errorf("Expected no stale references, got %v", stale)
+	}
+
+	memory.Undo()
+	memory.Undo()
+	if performance, _ := memory.GetPerformance(perf); performance.data.Bank_slot_a != validProgramBank || memory.StaleReferences() != nil {
+		t.Errorf("Expected Undo to restore the references along with the programs")
+	}
+}
+
+// Blade run at its own location and the init program at 0:000, in slots A and B of a performance at 0:000.
+func referencesMemory(t *testing.T) (*PatchMemory, MemoryLocation) {
+	memory := populatedMemory(t, "Program-BladeRun     ZON-1.18.syx")
+	memory.set(patchRef{ProgramT, MemoryT, index(0, 0)}, NewInitProgram())
+	perf := MemoryLocation{0, 0}
+	blank := MemoryLocation{0, 1}
+
+	if err := memory.ComposePerformance(MemoryLocation{validProgramBank, validProgramLocation}, MemoryLocation{0, 0}, blank, blank, ComposeOptions{Destination: perf}); err != nil {
+		t.Fatal(err)
+	}
+	return memory, perf
+}
@@ -0,0 +1,126 @@
+package nordlead3
+
+import (
+	"fmt"
+	"strings"
+)
+
+// importOutcomes: what became of a message offered for import
+const (
+	OutcomeImported        ImportOutcome = iota // loaded into a blank location
+	OutcomeOverwritten                          // loaded, replacing the patch which was there
+	OutcomeSkippedOccupied                      // not loaded, as the location was occupied and the conflict policy skipped it or aborted
+	OutcomeChecksumFailed                       // the dump is corrupt
+	OutcomeWrongLength                          // the message is too short or too long for its dump type
+	OutcomeTypeMismatch                         // a program where a performance was expected, or vice versa
+	OutcomeRejected                             // refused for another reason, given by Err
+)
+
+type ImportOutcome int
+
+// Where a patch is held. For slots, Bank is 0 and Location is the slot index.
+type PatchLocation struct {
+	Source SourceType
+	MemoryLocation
+}
+
+// Describes one message of an imported stream. Messages too short to hold a header leave the name, type
+// and locations zero.
+type ImportEntry struct {
+	Offset      int64 // of the message's F0 in the sysex stream, or for JSON, the position of the patch in the document
+	Name        string
+	PatchType   PatchType
+	Source      PatchLocation // as recorded in the dump
+	Destination PatchLocation // where it was, or would have been, loaded
+	Outcome     ImportOutcome
+	Err         error // nil if the patch was loaded
+}
+
+type ImportReport struct {
+	Entries []ImportEntry
+}
+
+var outcomeNames = []string{"imported", "overwritten", "skipped-occupied", "checksum-failed", "wrong-length", "type-mismatch", "rejected"}
+
+func (outcome ImportOutcome) String() string {
+	return enumString(outcomeNames, uint(outcome))
+}
+
+func (location PatchLocation) String() string {
+	if location.Source == SlotT {
+		return fmt.Sprintf("slot %d", location.Location)
+	}
+	return fmt.Sprintf("%d:%03d", location.Bank, location.Location)
+}
+
+// The number of patches loaded, whether into blank locations or over existing ones.
+func (report *ImportReport) NumImported() int {
+	result := 0
+	for _, entry := range report.Entries {
+		if entry.Err == nil {
+			result++
+		}
+	}
+	return result
+}
+
+func (report *ImportReport) NumRejected() int {
+	return len(report.Entries) - report.NumImported()
+}
+
+// One line per message, e.g. `0x00000105: program     3:004 -> 3:004 "Blade run    ZON" imported`.
+func (report *ImportReport) String() string {
+	var lines []string
+
+	for _, entry := range report.Entries {
+		line := fmt.Sprintf("%#08x: %-11s %s -> %s %q %s", entry.Offset, entry.PatchType, entry.Source, entry.Destination, entry.Name, entry.Outcome)
+		if entry.Err != nil {
+			line += fmt.Sprintf(" (%v)", entry.Err)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// helpers
+
+// Appends the entries of other, shifting their offsets by offset.
+func (report *ImportReport) append(other *ImportReport, offset int64) {
+	for _, entry := range other.Entries {
+		entry.Offset += offset
+		report.Entries = append(report.Entries, entry)
+	}
+}
+
+// Records the result of importing a patch to dest. replaced tells whether it took the place of another patch.
+func (report *ImportReport) add(entry ImportEntry, dest patchRef, replaced bool, err error) {
+	entry.Destination = dest.patchLocation()
+	entry.Err = err
+
+	switch err {
+	case nil:
+		if replaced {
+			entry.Outcome = OutcomeOverwritten
+		} else {
+			entry.Outcome = OutcomeImported
+		}
+	case ErrMemoryOccupied, ErrConflictAborted:
+		entry.Outcome = OutcomeSkippedOccupied
+	case ErrChecksumMismatch:
+		entry.Outcome = OutcomeChecksumFailed
+	case ErrWrongLength, ErrNotADump:
+		entry.Outcome = OutcomeWrongLength
+	case ErrImportTypeMismatch:
+		entry.Outcome = OutcomeTypeMismatch
+	default:
+		entry.Outcome = OutcomeRejected
+	}
+	report.Entries = append(report.Entries, entry)
+}
+
+func (ref *patchRef) patchLocation() PatchLocation {
+	if ref.source == SlotT {
+		return PatchLocation{SlotT, MemoryLocation{0, ref.index}}
+	}
+	return PatchLocation{ref.source, MemoryLocation{ref.bank(), ref.location()}}
+}
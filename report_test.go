@@ -0,0 +1,70 @@
+package nordlead3
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestImportReportOutcomes(t *testing.T) {
+	valid := validProgramSysex(t)
+	corrupt := append([]byte(nil), valid...)
+	corrupt[len(corrupt)-10] ^= 0x01
+	short := append(append([]byte(nil), valid[:200]...), valid[210:]...)
+
+	var stream []byte
+	for _, message := range [][]byte{valid, valid, corrupt, short} {
+		stream = append(stream, message...)
+	}
+
+	memory := new(PatchMemory)
+	report, err := memory.Import(bytes.NewReader(stream), ConflictSkip)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []ImportOutcome{OutcomeImported, OutcomeSkippedOccupied, OutcomeChecksumFailed, OutcomeWrongLength}
+	if len(report.Entries) != len(expected) {
+		t.Fatalf("Expected %d entries, got:\n%s", len(expected), report)
+	}
+	offset := int64(0)
+	for i, entry := range report.Entries {
+		if entry.Outcome != expected[i] || entry.Offset != offset {
+			t.Errorf("Entry %d: expected %s at %d, got %s at %d", i, expected[i], offset, entry.Outcome, entry.Offset)
+		}
+		offset += int64(len(valid))
+	}
+
+	first := report.Entries[0]
+	location := PatchLocation{MemoryT, MemoryLocation{validProgramBank, validProgramLocation}}
+	if first.Name != validProgramName || first.PatchType != ProgramT || first.Source != location || first.Destination != location || first.Err != nil {
+		t.Errorf("Unexpected entry for the valid program: %+v", first)
+	}
+	if report.NumImported() != 1 || report.NumRejected() != 3 {
+		t.Errorf("Expected 1 imported and 3 rejected, got %d and %d", report.NumImported(), report.NumRejected())
+	}
+	if !strings.Contains(report.String(), `3:004 -> 3:004 "Blade run    ZON" skipped-occupied (`) {
+		t.Errorf("Unexpected report:\n%s", report)
+	}
+}
+
+func TestImportReportOverwritesAndMismatches(t *testing.T) {
+	memory := new(PatchMemory)
+	stream := append(validProgramSysex(t), validProgramSysex(t)...)
+
+	report, _ := memory.Import(bytes.NewReader(stream), ConflictOverwrite)
+	if report.Entries[0].Outcome != OutcomeImported || report.Entries[1].Outcome != OutcomeOverwritten {
+		t.Errorf("Expected the second copy to overwrite the first, got:\n%s", report)
+	}
+
+	report, _ = memory.ImportTo(bytes.NewReader(validPerformanceSysex(t)), ProgramT, MemoryLocation{0, 0}, ConflictSkip)
+	entry := report.Entries[0]
+	if entry.Outcome != OutcomeTypeMismatch || entry.PatchType != PerformanceT || entry.Destination != (PatchLocation{MemoryT, MemoryLocation{0, 0}}) {
+		t.Errorf("Expected a type mismatch for a performance imported as a program, got:\n%s", report)
+	}
+
+	report, _ = memory.ImportTo(bytes.NewReader(stream), ProgramT, MemoryLocation{0, 126}, ConflictSkip)
+	if report.Entries[0].Destination.Location != 126 || report.Entries[1].Destination.Location != 127 {
+		t.Errorf("Expected consecutive destinations, got:\n%s", report)
+	}
+}
@@ -0,0 +1,225 @@
+package nordlead3
+
+import (
+	"bufio"
+	"encoding"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// A patch sheet is a line-oriented text form of a program, meant to be read, diffed and edited by hand:
+//
+//	# Comments run from a '#' to the end of the line
+//	name     = "Blade run    ZON"
+//	category = Pad
+//	version  = 1.18
+//
+//	[Oscillators]
+//	Osc1_waveform = Pulse
+//	Osc1_shape    = 0
+//
+// Every parameter is written as "path = value", grouped by section. Switches are 0 or 1 and enums are
+// written by name, though their numbers are accepted as well. Fields left out of a sheet keep the
+// values of NewInitProgram.
+
+var sheetSections = []string{"Oscillators", "LFOs", "Envelopes", "Filter", "Arpeggiator", "Morphs", "Voice"}
+
+// A line of a patch sheet which could not be parsed.
+type SheetError struct {
+	Line int
+	Err  error
+}
+
+func (e SheetError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Writes the program as a patch sheet.
+func (program *Program) WriteSheet(writer io.Writer) error {
+	if program == nil {
+		return ErrUninitialized
+	}
+	root := reflect.ValueOf(program.data).Elem()
+
+	bySection := make(map[string][]Parameter)
+	for _, parameter := range program.Parameters() {
+		section := sheetSection(parameter.Path)
+		bySection[section] = append(bySection[section], parameter)
+	}
+
+	buf := bufio.NewWriter(writer)
+	fmt.Fprintf(buf, "name     = %s\n", strconv.Quote(nameToString(program.name)))
+	fmt.Fprintf(buf, "category = %s\n", categoryToString(program.category))
+	fmt.Fprintf(buf, "version  = %1.2f\n", program.version)
+
+	for _, section := range sheetSections {
+		parameters := bySection[section]
+		width := 0
+		for _, parameter := range parameters {
+			width = max(width, len(parameter.Path))
+		}
+
+		fmt.Fprintf(buf, "\n[%s]\n", section)
+		for _, parameter := range parameters {
+			value, err := sheetValue(root, parameter.Path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(buf, "%-*s = %s\n", width, parameter.Path, value)
+		}
+	}
+	return buf.Flush()
+}
+
+// Reads a program from a patch sheet. Errors are reported as a SheetError naming the offending line.
+func ParseProgramSheet(input io.Reader) (*Program, error) {
+	program := NewInitProgram()
+	root := reflect.ValueOf(program.data).Elem()
+
+	scanner := bufio.NewScanner(input)
+	for line := 1; scanner.Scan(); line++ {
+		if err := program.parseSheetLine(root, scanner.Text()); err != nil {
+			return nil, SheetError{line, err}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return program, nil
+}
+
+// helpers
+
+func (program *Program) parseSheetLine(root reflect.Value, text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" || strings.HasPrefix(text, "#") {
+		return nil
+	}
+	if strings.HasPrefix(text, "[") {
+		header := stripComment(text)
+		if !strings.HasSuffix(header, "]") {
+			return fmt.Errorf("unterminated section header %q", header)
+		}
+		section := strings.TrimSpace(header[1 : len(header)-1])
+		for _, known := range sheetSections {
+			if section == known {
+				return nil
+			}
+		}
+		return fmt.Errorf("unknown section %q", section)
+	}
+
+	equals := strings.Index(text, "=")
+	if equals < 0 {
+		return fmt.Errorf("expected \"parameter = value\", got %q", text)
+	}
+	key := strings.TrimSpace(text[:equals])
+	value := strings.TrimSpace(text[equals+1:])
+
+	switch key {
+	case "name":
+		quoted := value
+		if end := strings.LastIndex(value, "\""); end > 0 {
+			quoted = value[:end+1]
+		}
+		unquoted, err := strconv.Unquote(quoted)
+		if err != nil || stripComment(value[len(quoted):]) != "" {
+			return fmt.Errorf("expected a quoted name, got %s", value)
+		}
+		name, err := nameFromString(unquoted)
+		if err != nil || unquoted == "" {
+			return ErrInvalidName
+		}
+		program.name = name
+		return nil
+	case "category":
+		category, err := categoryFromString(stripComment(value))
+		if err != nil {
+			return err
+		}
+		program.category = category
+		return nil
+	case "version":
+		version, err := strconv.ParseFloat(stripComment(value), 64)
+		if err != nil {
+			return err
+		}
+		if versionX100(version) <= 0 || versionX100(version) > math.MaxUint16 {
+			return ErrUnsupportedVersion // does not fit the version number of a dump
+		}
+		program.version = version
+		program.data.Version_number = uint(versionX100(version))
+		return nil
+	}
+
+	number, err := parseSheetValue(root, key, stripComment(value))
+	if err != nil {
+		return err
+	}
+	return setParameter(root, key, number)
+}
+
+// Parses a number, or the name of an enum value.
+func parseSheetValue(root reflect.Value, path string, value string) (int, error) {
+	if number, err := strconv.Atoi(value); err == nil {
+		return number, nil
+	}
+
+	rf, _, err := lookupParameter(root, path)
+	if err != nil {
+		return 0, err
+	}
+	enum := reflect.New(rf.Type())
+	unmarshaler, ok := enum.Interface().(encoding.TextUnmarshaler)
+	if !ok {
+		return 0, fmt.Errorf("%s takes a number, got %q", path, value)
+	}
+	if err := unmarshaler.UnmarshalText([]byte(value)); err != nil {
+		return 0, fmt.Errorf("%s: %v", path, err)
+	}
+	return int(enum.Elem().Uint()), nil
+}
+
+// Writes enums by name, everything else by number.
+func sheetValue(root reflect.Value, path string) (string, error) {
+	rf, _, err := lookupParameter(root, path)
+	if err != nil {
+		return "", err
+	}
+	if marshaler, ok := rf.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		return string(text), err
+	}
+	value, err := getParameter(root, path)
+	return strconv.Itoa(value), err
+}
+
+func sheetSection(path string) string {
+	switch {
+	case strings.Contains(path, "_morph_params."):
+		return "Morphs"
+	case strings.HasPrefix(path, "Osc"):
+		return "Oscillators"
+	case strings.HasPrefix(path, "Lfo"), strings.HasPrefix(path, "Vibrato"):
+		return "LFOs"
+	case strings.Contains(path, "_env"):
+		return "Envelopes"
+	case strings.HasPrefix(path, "Filt"):
+		return "Filter"
+	case strings.HasPrefix(path, "Arp"), strings.HasPrefix(path, "Sub_arp"), strings.HasPrefix(path, "Chord"):
+		return "Arpeggiator"
+	default:
+		return "Voice"
+	}
+}
+
+func stripComment(value string) string {
+	if hash := strings.Index(value, "#"); hash >= 0 {
+		value = value[:hash]
+	}
+	return strings.TrimSpace(value)
+}
@@ -0,0 +1,115 @@
+package nordlead3
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSheetRoundTrip(t *testing.T) {
+	memory := populatedMemory(t, "AllPrograms.syx")
+
+	for _, ref := range memory.initializedRefs(ProgramT) {
+		patch, _ := memory.get(ref)
+		program := patch.(*Program)
+
+		var sheet strings.Builder
+		if err := program.WriteSheet(&sheet); err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := ParseProgramSheet(strings.NewReader(sheet.String()))
+		if err != nil {
+			t.Fatalf("%s: %v", program.PrintableName(), err)
+		}
+		if *parsed.data != *program.data || parsed.name != program.name || parsed.category != program.category || parsed.version != program.version {
+			t.Errorf("%s did not survive the patch sheet:\n%s", program.PrintableName(), sheet.String())
+		}
+	}
+}
+
+func TestSheetRoundTripsUnknownVersions(t *testing.T) {
+	program := NewInitProgram()
+	program.version = 1.22
+	program.data.Version_number = 122
+
+	var sheet strings.Builder
+	program.WriteSheet(&sheet)
+	parsed, err := ParseProgramSheet(strings.NewReader(sheet.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.version != 1.22 || *parsed.data != *program.data {
+		t.Errorf("Expected a v1.22 program to survive the patch sheet, got v%1.2f", parsed.version)
+	}
+}
+
+func TestSheetIsGroupedAndReadable(t *testing.T) {
+	memory := populatedMemory(t, "Program-BladeRun     ZON-1.18.syx")
+	program, _ := memory.GetProgram(MemoryLocation{validProgramBank, validProgramLocation})
+
+	var sheet strings.Builder
+	program.WriteSheet(&sheet)
+	text := sheet.String()
+
+	expected := []string{"name     = \"Blade run    ZON\"\n", "version  = 1.18\n", "\n[Oscillators]\n", "\nFilt1_type ", " = Band reject\n", "\nMono_mode "}
+	for _, fragment := range expected {
+		if !strings.Contains(text, fragment) {
+			t.Errorf("Expected %q in the sheet:\n%s", fragment, text)
+		}
+	}
+	if strings.Index(text, "[Filter]") > strings.Index(text, "Filt1_type") || strings.Index(text, "[Morphs]") > strings.Index(text, "Wheel_morph_params.Oscmix") {
+		t.Errorf("Parameters are not in their sections:\n%s", text)
+	}
+}
+
+func TestParseSheetFallsBackToInit(t *testing.T) {
+	sheet := `# A hand written sheet
+name = "Hand # made"  # names may hold a hash
+category = Lead
+
+[Filter]
+Filt1_type      = Highpass # was Lowpass
+Filt_frequency1 = 87
+
+[Morphs]
+Wheel_morph_params.Filt_frequency1 = -30
+`
+	program, err := ParseProgramSheet(strings.NewReader(sheet))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := NewInitProgram()
+	expected.SetName("Hand # made")
+	expected.SetCategory(int(program.category))
+	expected.data.Filt1_type = FilterHighpass
+	expected.data.Filt_frequency1 = 87
+	expected.data.Wheel_morph_params.Filt_frequency1 = -30
+	if *program.data != *expected.data || program.name != expected.name || program.PrintableCategory() != "Lead" {
+		t.Errorf("Parsed program differs from the expected one")
+	}
+}
+
+func TestParseSheetReportsLine(t *testing.T) {
+	cases := []struct {
+		sheet string
+		line  int
+	}{
+		{"Filt_frequency1 = 87\nFilt_frequency3 = 1\n", 2},
+		{"\n\nFilt1_slope = 3", 3},
+		{"Filt1_type = Wobble", 1},
+		{"Mono_mode = on", 1},
+		{"[Oscilators]", 1},
+		{"Osc1_shape 12", 1},
+		{"name = Unquoted", 1},
+		{"name = \"Far too long for a name\"", 1},
+		{"version = 0", 1},
+		{"version = 700", 1},
+	}
+
+	for _, c := range cases {
+		_, err := ParseProgramSheet(strings.NewReader(c.sheet))
+		if sheetErr, ok := err.(SheetError); !ok || sheetErr.Line != c.line {
+			t.Errorf("%q: expected an error on line %d, got %v", c.sheet, c.line, err)
+		}
+	}
+}
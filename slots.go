@@ -0,0 +1,196 @@
+package nordlead3
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The number of program slots in a performance, A to D.
+const NumSlots = 4
+
+// A set of performance slots, 1 = A, 2 = B, 4 = C, 8 = D.
+type SlotMask uint
+
+// One slot of a performance, as PerformanceData.Slot returns it. The fields point into the PerformanceData,
+// so that setting through them edits the performance; the masks shared with the other slots are reached
+// through the methods.
+type PerformanceSlot struct {
+	MidiChannel  *uint // 0-16 0 = Off
+	AudioChannel *uint // 0-5
+	Bank         *uint // where the program was taken from
+	Program      *uint
+	Name         *PatchName
+	Data         *ProgramData
+
+	index       int
+	performance *PerformanceData
+}
+
+func (mask SlotMask) Has(slot int) bool {
+	return mask&(1<<uint(slot)) != 0
+}
+
+func (mask *SlotMask) Set(slot int) {
+	*mask |= 1 << uint(slot)
+}
+
+func (mask *SlotMask) Clear(slot int) {
+	*mask &^= 1 << uint(slot)
+}
+
+// Lists the slots, e.g. "A+C", or "none".
+func (mask SlotMask) String() string {
+	if mask >= 1<<NumSlots {
+		return fmt.Sprintf("%#02x", uint(mask))
+	}
+	var slots []string
+	for slot := 0; slot < NumSlots; slot++ {
+		if mask.Has(slot) {
+			slots = append(slots, string(rune('A'+slot)))
+		}
+	}
+	if slots == nil {
+		return "none"
+	}
+	return strings.Join(slots, "+")
+}
+
+// Returns a view of the slot (0 to 3 for A to D). It panics for any other slot, as indexing would.
+func (data *PerformanceData) Slot(slot int) PerformanceSlot {
+	return PerformanceSlot{
+		MidiChannel:  [NumSlots]*uint{&data.Midi_channel_slot_a, &data.Midi_channel_slot_b, &data.Midi_channel_slot_c, &data.Midi_channel_slot_d}[slot],
+		AudioChannel: [NumSlots]*uint{&data.Audio_channel_slot_a, &data.Audio_channel_slot_b, &data.Audio_channel_slot_c, &data.Audio_channel_slot_d}[slot],
+		Bank:         [NumSlots]*uint{&data.Bank_slot_a, &data.Bank_slot_b, &data.Bank_slot_c, &data.Bank_slot_d}[slot],
+		Program:      [NumSlots]*uint{&data.Program_slot_a, &data.Program_slot_b, &data.Program_slot_c, &data.Program_slot_d}[slot],
+		Name:         [NumSlots]*PatchName{&data.Patchname_slot_a, &data.Patchname_slot_b, &data.Patchname_slot_c, &data.Patchname_slot_d}[slot],
+		Data:         [NumSlots]*ProgramData{&data.Patch_data_a, &data.Patch_data_b, &data.Patch_data_c, &data.Patch_data_d}[slot],
+		index:        slot,
+		performance:  data,
+	}
+}
+
+func (view PerformanceSlot) Enabled() bool {
+	return view.performance.Enabled_slots.Has(view.index)
+}
+
+func (view PerformanceSlot) SetEnabled(on bool) {
+	setSlot(&view.performance.Enabled_slots, view.index, on)
+}
+
+func (view PerformanceSlot) Sustain() bool {
+	return view.performance.Sustain_enable.Has(view.index)
+}
+
+func (view PerformanceSlot) SetSustain(on bool) {
+	setSlot(&view.performance.Sustain_enable, view.index, on)
+}
+
+func (view PerformanceSlot) Pitchbend() bool {
+	return view.performance.Pitchbend_enable.Has(view.index)
+}
+
+func (view PerformanceSlot) SetPitchbend(on bool) {
+	setSlot(&view.performance.Pitchbend_enable, view.index, on)
+}
+
+func (view PerformanceSlot) Modwheel() bool {
+	return view.performance.Modwheel_enable.Has(view.index)
+}
+
+func (view PerformanceSlot) SetModwheel(on bool) {
+	setSlot(&view.performance.Modwheel_enable, view.index, on)
+}
+
+// The program memory location the slot refers to.
+func (view PerformanceSlot) Location() MemoryLocation {
+	return MemoryLocation{int(*view.Bank), int(*view.Program)}
+}
+
+func (view PerformanceSlot) SetLocation(ml MemoryLocation) {
+	*view.Bank, *view.Program = uint(ml.Bank), uint(ml.Location)
+}
+
+// Saves copies of the four slot programs of the performance at ml into consecutive locations of program
+// memory starting at dest, and points the performance's slot references at them. Nothing is saved if any
+// of the locations is occupied or they would run past the last bank. This is a single operation for Undo.
+func (memory *PatchMemory) ExplodePerformance(ml MemoryLocation, dest MemoryLocation) error {
+	ref := patchRef{PerformanceT, MemoryT, ml.index()}
+	original, err := memory.GetPerformance(ml)
+	if err != nil {
+		return err
+	}
+
+	var programs []patch
+	exploded := original.clone()
+	for slot := 0; slot < NumSlots; slot++ {
+		program, _ := original.SlotProgram(slot)
+		programs = append(programs, program)
+		if at := index(dest.Bank, dest.Location+slot); valid(ProgramT, MemoryT, at) {
+			exploded.setSlotProgram(slot, program, MemoryLocation{bank(at), location(at)})
+		}
+	}
+
+	return memory.atomically(describe("explode", ref), func() error {
+		if err := memory.place(programs, patchRef{ProgramT, MemoryT, dest.index()}); err != nil {
+			return err
+		}
+		memory.put(ref, exploded)
+		return nil
+	})
+}
+
+// Returns a copy of the program in the slot (0 to 3 for A to D), named as the performance records it and
+// at the performance's OS version. Performances do not record the categories of their programs, so the
+// copy has the category of NewInitProgram.
+func (performance *Performance) SlotProgram(slot int) (*Program, error) {
+	if performance == nil {
+		return nil, ErrUninitialized
+	}
+	if slot < 0 || slot >= NumSlots {
+		return nil, ErrInvalidLocation
+	}
+
+	view := performance.data.Slot(slot)
+	program := &Program{
+		name:     [16]byte(*view.Name),
+		category: initCategory,
+		version:  performance.version,
+		data:     new(ProgramData),
+	}
+	*program.data = *view.Data
+	program.data.Version_number = uint(versionX100(performance.version))
+	return program, nil
+}
+
+// Puts a copy of the program, converted to the performance's OS version, in the slot (0 to 3 for A to D),
+// along with its name. The slot's bank and program references are pointed at ml, which should be where the
+// program is held in memory.
+func (performance *Performance) SetSlotProgram(slot int, program *Program, ml MemoryLocation) error {
+	if performance == nil || program == nil {
+		return ErrUninitialized
+	}
+	if slot < 0 || slot >= NumSlots || !valid(ProgramT, MemoryT, ml.index()) {
+		return ErrInvalidLocation
+	}
+	performance.setSlotProgram(slot, program, ml)
+	return nil
+}
+
+// helpers
+
+func (performance *Performance) setSlotProgram(slot int, program *Program, ml MemoryLocation) {
+	view := performance.data.Slot(slot)
+
+	*view.Data = *program.data
+	view.Data.Version_number = 0 // embedded programs carry no version number
+	*view.Name = PatchName(program.name)
+	view.SetLocation(ml)
+}
+
+func setSlot(mask *SlotMask, slot int, on bool) {
+	if on {
+		mask.Set(slot)
+	} else {
+		mask.Clear(slot)
+	}
+}
@@ -0,0 +1,130 @@
+package nordlead3
+
+import (
+	"testing"
+)
+
+func TestSlotProgram(t *testing.T) {
+	memory := populatedMemory(t, "Performance-Orchestra     HN.syx")
+	performance, _ := memory.GetPerformance(MemoryLocation{validPerformanceBank, validPerformanceLocation})
+
+	program, err := performance.SlotProgram(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := performance.data.Patch_data_b
+	expected.Version_number = uint(versionX100(validPerformanceVersion))
+	if *program.data != expected || PatchName(program.name) != performance.data.Patchname_slot_b || program.version != validPerformanceVersion {
+		t.Errorf("Slot B was not extracted as it is held in the performance")
+	}
+
+	program.data.Filt_resonance++
+	if performance.data.Patch_data_b.Filt_resonance == program.data.Filt_resonance {
+		t.Errorf("Editing the extracted program changed the performance")
+	}
+	if _, err := performance.SlotProgram(NumSlots); err != ErrInvalidLocation {
+		t.Errorf("Expected ErrInvalidLocation for slot %d, got %v", NumSlots, err)
+	}
+}
+
+func TestSetSlotProgram(t *testing.T) {
+	memory := populatedMemory(t, "Program-BladeRun     ZON-1.18.syx")
+	blade, _ := memory.GetProgram(MemoryLocation{validProgramBank, validProgramLocation})
+	performance := NewInitPerformance()
+
+	if err := performance.SetSlotProgram(2, blade, MemoryLocation{validProgramBank, validProgramLocation}); err != nil {
+		t.Fatal(err)
+	}
+	data := performance.data
+	if data.Patchname_slot_c != PatchName(blade.name) || data.Bank_slot_c != validProgramBank || data.Program_slot_c != validProgramLocation || data.Patch_data_c.Version_number != 0 {
+		t.Errorf("Slot C does not describe the program")
+	}
+
+	upgraded := blade.clone()
+	upgraded.Upgrade()
+	if extracted, _ := performance.SlotProgram(2); *extracted.data != *upgraded.data || extracted.PrintableName() != validProgramName {
+		t.Errorf("Expected the 1.18 program to come back converted to 1.20")
+	}
+	if blade.version != validProgramVersion {
+		t.Errorf("Setting the slot converted the original program")
+	}
+
+	if err := performance.SetSlotProgram(0, blade, MemoryLocation{NumProgramBanks, 0}); err != ErrInvalidLocation {
+		t.Errorf("Expected ErrInvalidLocation for a reference past the last bank, got %v", err)
+	}
+}
+
+func TestExplodePerformance(t *testing.T) {
+	memory := populatedMemory(t, "Performance-Orchestra     HN.syx")
+	ml := MemoryLocation{validPerformanceBank, validPerformanceLocation}
+	performance, _ := memory.GetPerformance(ml)
+	dest := MemoryLocation{2, 10}
+
+	if err := memory.ExplodePerformance(ml, dest); err != nil {
+		t.Fatal(err)
+	}
+	exploded, _ := memory.GetPerformance(ml)
+	for slot := 0; slot < NumSlots; slot++ {
+		at := MemoryLocation{dest.Bank, dest.Location + slot}
+		program, err := memory.GetProgram(at)
+		expected, _ := performance.SlotProgram(slot)
+		if err != nil || *program.data != *expected.data || program.name != expected.name {
+			t.Errorf("Expected slot %d at %v, got %v (%v)", slot, at, program, err)
+		}
+		if refers := exploded.data.Slot(slot).Location(); refers != at {
+			t.Errorf("Expected slot %d to refer to %v, got %v", slot, at, refers)
+		}
+	}
+
+	memory.Undo()
+	if memory.NumPrograms(true) != 0 || memory.at(patchRef{PerformanceT, MemoryT, ml.index()}) != performance {
+		t.Errorf("Expected Undo to remove the programs and restore the performance")
+	}
+
+	memory.set(patchRef{ProgramT, MemoryT, index(2, 12)}, NewInitProgram())
+	if err := memory.ExplodePerformance(ml, dest); err != ErrMemoryOccupied || memory.NumPrograms(true) != 1 {
+		t.Errorf("Expected ErrMemoryOccupied with nothing saved, got %v", err)
+	}
+	if err := memory.ExplodePerformance(ml, MemoryLocation{NumProgramBanks - 1, BankSize - 2}); err != ErrMemoryOverflow {
+		t.Errorf("Expected ErrMemoryOverflow, got %v", err)
+	}
+}
+
+func TestSlotMask(t *testing.T) {
+	var mask SlotMask
+	mask.Set(0)
+	mask.Set(2)
+	if !mask.Has(0) || mask.Has(1) || !mask.Has(2) || mask.String() != "A+C" {
+		t.Errorf("Expected slots A and C, got %v", mask)
+	}
+	mask.Clear(0)
+	if mask != 0x04 || SlotMask(0).String() != "none" {
+		t.Errorf("Expected only slot C, got %v", mask)
+	}
+}
+
+func TestPerformanceSlot(t *testing.T) {
+	memory := populatedMemory(t, "Performance-Orchestra     HN.syx")
+	performance, _ := memory.GetPerformance(MemoryLocation{validPerformanceBank, validPerformanceLocation})
+	data := performance.data
+
+	slot := data.Slot(3)
+	if *slot.MidiChannel != data.Midi_channel_slot_d || *slot.Name != data.Patchname_slot_d || slot.Location() != (MemoryLocation{int(data.Bank_slot_d), int(data.Program_slot_d)}) {
+		t.Errorf("Expected the view to show slot D")
+	}
+	if slot.Enabled() != data.Enabled_slots.Has(3) || slot.Sustain() != data.Sustain_enable.Has(3) {
+		t.Errorf("Expected the view to read the masks at slot D")
+	}
+
+	modwheel := data.Modwheel_enable
+	*slot.AudioChannel = 5
+	slot.Data.Filt_resonance = 99
+	slot.SetModwheel(!slot.Modwheel())
+	slot.SetLocation(MemoryLocation{6, 100})
+	if data.Audio_channel_slot_d != 5 || data.Patch_data_d.Filt_resonance != 99 || data.Bank_slot_d != 6 || data.Program_slot_d != 100 {
+		t.Errorf("Expected editing through the view to edit slot D")
+	}
+	if data.Modwheel_enable != modwheel^0x08 {
+		t.Errorf("Expected SetModwheel to change only slot D's bit")
+	}
+}
@@ -0,0 +1,249 @@
+package nordlead3
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"time"
+)
+
+// Standard MIDI Files wrap each sysex message in an F0 event holding its length. Long messages may be
+// split into an F0 packet followed by F7 continuation packets, the last of which ends with the F7 byte.
+
+const (
+	smfTicksPerQuarter = 500    // at smfTempo, one tick per millisecond
+	smfTempo           = 500000 // microseconds per quarter note, the SMF default of 120 bpm
+	smfMetaEvent       = 0xFF
+	smfMetaTempo       = 0x51
+	smfMetaEndOfTrack  = 0x2F
+)
+
+var (
+	smfHeaderID = []byte("MThd")
+	smfTrackID  = []byte("MTrk")
+)
+
+// An SMFWriter collects the sysex written to it and, when closed, writes it out as a format 0 Standard
+// MIDI File with each message delay after the previous one. Giving the unit time between messages keeps
+// a DAW playing the file back from overflowing its receive buffer. Any of the exports can target it:
+//
+//	smf := NewSMFWriter(file, 200*time.Millisecond)
+//	memory.ExportPerformanceBank(2, smf)
+//	err := smf.Close()
+type SMFWriter struct {
+	writer io.Writer
+	delay  time.Duration
+	sysex  bytes.Buffer
+}
+
+func NewSMFWriter(writer io.Writer, delay time.Duration) *SMFWriter {
+	return &SMFWriter{writer: writer, delay: delay}
+}
+
+func (smf *SMFWriter) Write(p []byte) (int, error) {
+	return smf.sysex.Write(p)
+}
+
+// Writes the file. Closing does not close the underlying writer.
+func (smf *SMFWriter) Close() error {
+	var track bytes.Buffer
+	delayTicks := uint32((smf.delay + time.Millisecond - 1) / time.Millisecond)
+
+	track.Write([]byte{0, smfMetaEvent, smfMetaTempo, 3, smfTempo >> 16, smfTempo >> 8 & 0xFF, smfTempo & 0xFF})
+	for i, message := range splitMessages(smf.sysex.Bytes()) {
+		if i == 0 {
+			track.Write(varLen(0))
+		} else {
+			track.Write(varLen(delayTicks))
+		}
+		track.WriteByte(sysexStart)
+		track.Write(varLen(uint32(len(message) - 1)))
+		track.Write(message[1:])
+	}
+	track.Write([]byte{0, smfMetaEvent, smfMetaEndOfTrack, 0})
+
+	var file bytes.Buffer
+	writeChunk(&file, smfHeaderID, []byte{0, 0, 0, 1, smfTicksPerQuarter >> 8, smfTicksPerQuarter & 0xFF})
+	writeChunk(&file, smfTrackID, track.Bytes())
+	_, err := smf.writer.Write(file.Bytes())
+	return err
+}
+
+// Writes every performance, then every program, as a Standard MIDI File. See SMFWriter.
+func (memory *PatchMemory) ExportSMF(delay time.Duration, writer io.Writer) error {
+	refs := append(memory.initializedRefs(PerformanceT), memory.initializedRefs(ProgramT)...)
+	smf := NewSMFWriter(writer, delay)
+	if err := memory.exportLocations(refs, smf); err != nil {
+		return err
+	}
+	return smf.Close()
+}
+
+// helpers
+
+// Returns the raw sysex in input, unwrapping it first if input is a Standard MIDI File.
+func sysexStream(input io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(input)
+	if magic, _ := buffered.Peek(len(smfHeaderID)); !bytes.Equal(magic, smfHeaderID) {
+		return buffered, nil
+	}
+
+	data, err := ioutil.ReadAll(buffered)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := readSMF(data)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(bytes.Join(messages, nil)), nil
+}
+
+// Returns the sysex messages of every track in the file, in file order, each from F0 to F7.
+func readSMF(data []byte) ([][]byte, error) {
+	var messages [][]byte
+
+	for len(data) > 0 {
+		if len(data) < 8 {
+			return nil, ErrInvalidSMF
+		}
+		id, length := data[:4], binary.BigEndian.Uint32(data[4:8])
+		if uint32(len(data)-8) < length {
+			return nil, ErrInvalidSMF
+		}
+		chunk := data[8 : 8+length]
+		data = data[8+length:]
+
+		switch {
+		case bytes.Equal(id, smfHeaderID):
+			if length < 6 || binary.BigEndian.Uint16(chunk) > 1 {
+				return nil, ErrInvalidSMF // format 2 files hold independent sequences, which is not how dumps are stored
+			}
+		case bytes.Equal(id, smfTrackID):
+			trackMessages, err := readTrack(chunk)
+			if err != nil {
+				return nil, err
+			}
+			messages = append(messages, trackMessages...)
+		}
+		// Other chunk types are skipped, as the standard asks.
+	}
+	return messages, nil
+}
+
+// Returns the sysex of one track, joining F7 continuation packets onto the F0 packet they continue.
+// F7 packets which continue nothing are escapes holding arbitrary bytes, and are kept if they hold a
+// complete message.
+func readTrack(track []byte) ([][]byte, error) {
+	var messages [][]byte
+	var pending []byte
+	var runningStatus byte
+
+	for len(track) > 0 {
+		_, n := readVarLen(track) // delta time
+		if n == 0 || len(track) == n {
+			return nil, ErrInvalidSMF
+		}
+		track = track[n:]
+
+		status := track[0]
+		switch {
+		case status == sysexStart || status == sysexEnd:
+			length, n := readVarLen(track[1:])
+			if n == 0 || uint32(len(track)-1-n) < length {
+				return nil, ErrInvalidSMF
+			}
+			packet := track[1+n : 1+n+int(length)]
+			track = track[1+n+int(length):]
+			runningStatus = 0
+
+			if status == sysexStart {
+				pending = append([]byte{sysexStart}, packet...)
+			} else if pending != nil {
+				pending = append(pending, packet...)
+			} else if len(packet) > 0 && packet[0] == sysexStart {
+				pending = append([]byte(nil), packet...)
+			} else {
+				continue
+			}
+			if len(pending) > 1 && pending[len(pending)-1] == sysexEnd {
+				messages = append(messages, pending)
+				pending = nil
+			}
+		case status == smfMetaEvent:
+			if len(track) < 2 {
+				return nil, ErrInvalidSMF
+			}
+			length, n := readVarLen(track[2:])
+			if n == 0 || uint32(len(track)-2-n) < length {
+				return nil, ErrInvalidSMF
+			}
+			track = track[2+n+int(length):]
+			runningStatus = 0
+		default:
+			if status >= 0x80 {
+				runningStatus = status
+				track = track[1:]
+			} else if runningStatus == 0 {
+				return nil, ErrInvalidSMF
+			}
+			numData := 2
+			if runningStatus&0xF0 == 0xC0 || runningStatus&0xF0 == 0xD0 {
+				numData = 1
+			}
+			if len(track) < numData {
+				return nil, ErrInvalidSMF
+			}
+			track = track[numData:]
+		}
+	}
+	return messages, nil
+}
+
+// Splits a stream of sysex into its messages, dropping anything between them.
+func splitMessages(sysex []byte) [][]byte {
+	var messages [][]byte
+
+	for {
+		si := bytes.IndexByte(sysex, sysexStart)
+		if si < 0 {
+			return messages
+		}
+		ei := bytes.IndexByte(sysex[si:], sysexEnd)
+		if ei < 0 {
+			return messages
+		}
+		messages = append(messages, sysex[si:si+ei+1])
+		sysex = sysex[si+ei+1:]
+	}
+}
+
+// Reads a variable-length quantity, returning it and the number of bytes it took, or 0 bytes if malformed.
+func readVarLen(data []byte) (value uint32, n int) {
+	for n < len(data) && n < 4 {
+		value = value<<7 | uint32(data[n]&0x7F)
+		n++
+		if data[n-1]&0x80 == 0 {
+			return value, n
+		}
+	}
+	return 0, 0
+}
+
+func varLen(value uint32) []byte {
+	result := []byte{byte(value & 0x7F)}
+	for value >>= 7; value > 0; value >>= 7 {
+		result = append([]byte{byte(value&0x7F | 0x80)}, result...)
+	}
+	return result
+}
+
+func writeChunk(buf *bytes.Buffer, id []byte, chunk []byte) {
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(chunk)))
+	buf.Write(id)
+	buf.Write(length[:])
+	buf.Write(chunk)
+}
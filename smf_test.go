@@ -0,0 +1,133 @@
+package nordlead3
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSMFRoundTrip(t *testing.T) {
+	memory := populatedMemory(t, "AllPrograms.syx")
+	helperLoadFromFile(t, memory, "PerfBank1.syx")
+
+	var smf bytes.Buffer
+	if err := memory.ExportSMF(150*time.Millisecond, &smf); err != nil {
+		t.Fatal(err)
+	}
+	reloaded := new(PatchMemory)
+	report, err := reloaded.Import(&smf, ConflictSkip)
+	if err != nil || report.NumRejected() != 0 || report.NumImported() != memory.NumPrograms(true)+memory.NumPerformances(true) {
+		t.Fatalf("Expected every patch back, got %d valid, %d invalid (%v)", report.NumImported(), report.NumRejected(), err)
+	}
+
+	var expected, received bytes.Buffer
+	memory.ExportAllPrograms(&expected)
+	reloaded.ExportAllPrograms(&received)
+	expectedBytes, receivedBytes := expected.Bytes(), received.Bytes()
+	binaryExpectEqual(t, &expectedBytes, &receivedBytes)
+}
+
+func TestSMFWriterSpacesMessages(t *testing.T) {
+	sysex, err := ioutil.ReadFile(filepath.Join("testdata", "ProgBank1.syx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file bytes.Buffer
+	smf := NewSMFWriter(&file, 40*time.Millisecond)
+	smf.Write(sysex)
+	if err := smf.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data := file.Bytes()
+	if !bytes.Equal(data[:14], []byte{'M', 'T', 'h', 'd', 0, 0, 0, 6, 0, 0, 0, 1, 0x01, 0xF4}) {
+		t.Fatalf("Unexpected header % x", data[:14])
+	}
+	track := data[22:]
+	var deltas []uint32
+	for len(track) > 0 {
+		delta, n := readVarLen(track)
+		deltas = append(deltas, delta)
+		length, m := readVarLen(track[n+1:])
+		if track[n] == smfMetaEvent {
+			length, m = readVarLen(track[n+2:])
+			m++
+		}
+		track = track[n+1+m+int(length):]
+	}
+
+	messages := splitMessages(sysex)
+	if len(deltas) != len(messages)+2 {
+		t.Fatalf("Expected %d events, got %d", len(messages)+2, len(deltas))
+	}
+	for i, delta := range deltas[2 : len(deltas)-1] {
+		if delta != 40 {
+			t.Errorf("Message %d is %d ms after the previous one", i+1, delta)
+		}
+	}
+}
+
+func TestImportSMFJoinsContinuationPackets(t *testing.T) {
+	sysex, err := ioutil.ReadFile(filepath.Join("testdata", "Program-Elektro         -1.20.syx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	split := len(sysex) / 3
+
+	// A format 1 file: a tempo track, and a track mixing notes (with running status) and a dump sent in three packets.
+	var tempo, track, file bytes.Buffer
+	tempo.Write([]byte{0, smfMetaEvent, smfMetaTempo, 3, 0x07, 0xA1, 0x20, 0, smfMetaEvent, smfMetaEndOfTrack, 0})
+	track.Write([]byte{0, 0x90, 60, 100, 10, 62, 100, 0, 0xC0, 5})
+	track.Write(append([]byte{0, sysexStart}, varLen(uint32(split-1))...))
+	track.Write(sysex[1:split])
+	track.Write([]byte{20, 0x80, 60, 0})
+	track.Write(append([]byte{10, sysexEnd}, varLen(uint32(split))...))
+	track.Write(sysex[split : 2*split])
+	track.Write(append([]byte{10, sysexEnd}, varLen(uint32(len(sysex)-2*split))...))
+	track.Write(sysex[2*split:])
+	track.Write([]byte{0, smfMetaEvent, smfMetaEndOfTrack, 0})
+	writeChunk(&file, smfHeaderID, []byte{0, 1, 0, 2, 0x01, 0xE0})
+	writeChunk(&file, smfTrackID, tempo.Bytes())
+	writeChunk(&file, smfTrackID, track.Bytes())
+
+	memory := new(PatchMemory)
+	report, err := memory.Import(&file, ConflictSkip)
+	if report.NumImported() != 1 || report.NumRejected() != 0 || err != nil {
+		t.Fatalf("Expected the program to be imported, got %s (%v)", report, err)
+	}
+
+	reference := populatedMemory(t, "Program-Elektro         -1.20.syx")
+	var expected, received bytes.Buffer
+	reference.ExportAllPrograms(&expected)
+	memory.ExportAllPrograms(&received)
+	if !bytes.Equal(expected.Bytes(), received.Bytes()) {
+		t.Errorf("Program differs after import from SMF")
+	}
+}
+
+func TestImportRejectsMalformedSMF(t *testing.T) {
+	file, err := os.Open(filepath.Join("testdata", "ProgBank1.syx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var smf bytes.Buffer
+	if err := new(PatchMemory).ExportSMF(0, &smf); err != ErrNoDataToWrite {
+		t.Errorf("Expected ErrNoDataToWrite for an empty memory, got %v", err)
+	}
+
+	memory := new(PatchMemory)
+	memory.Import(file, ConflictSkip)
+	memory.ExportSMF(0, &smf)
+	truncated := smf.Bytes()[:smf.Len()-100]
+
+	if _, err := new(PatchMemory).Import(bytes.NewReader(truncated), ConflictSkip); err != ErrInvalidSMF {
+		t.Errorf("Expected ErrInvalidSMF for a truncated file, got %v", err)
+	}
+	if _, _, err := new(PatchLibrary).Import(bytes.NewReader(truncated)); err != ErrInvalidSMF {
+		t.Errorf("Expected the library to reject the truncated file too, got %v", err)
+	}
+}
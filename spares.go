@@ -0,0 +1,180 @@
+package nordlead3
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// How one bit of a spare field behaves across a set of patches, as AnalyzeSpares reports it.
+type SpareBit struct {
+	Field        string        // e.g. "Spare7", or "Patch_data_b.Spare10" in a performance's slot program
+	Bit          int           // 0 being the least significant
+	Set          int           // how many of the patches have the bit set
+	Patches      int           // how many patches were analyzed
+	Correlations []Correlation // the parameters varying with the bit, strongest first
+}
+
+// How closely a parameter follows a spare bit, from -1 (always the opposite way) through 0 (unrelated)
+// to 1 (always together).
+type Correlation struct {
+	Parameter   string
+	Coefficient float64
+}
+
+func (spare SpareBit) String() string {
+	var correlations []string
+	for _, c := range spare.Correlations {
+		correlations = append(correlations, fmt.Sprintf("%s %+.2f", c.Parameter, c.Coefficient))
+	}
+	return fmt.Sprintf("%s bit %d: set in %d of %d [%s]", spare.Field, spare.Bit, spare.Set, spare.Patches, strings.Join(correlations, ", "))
+}
+
+// Looks for meaning in the spare fields of the library's patches of the given type, which may be gathered
+// from as many dumps as can be found, of the same locations or not. Every bit of a spare field that is set in some of the patches but
+// not in all of them is reported, in layout order, with the parameters and aliases it correlates with at
+// least as strongly as minimum (ignoring sign). The spare fields of the programs in a performance's slots
+// are analyzed along with its own, and program aliases apply to them. Bits covered by an alias are taken
+// as known, not spare.
+func (library *PatchLibrary) AnalyzeSpares(pt PatchType, minimum float64) []SpareBit {
+	var result []SpareBit
+	patches := library.patchData(pt)
+	root := reflect.New(dataType(pt)).Elem()
+	parameters := listParameters(root, "", 0)
+	registered := nestedAliases(root, "")
+
+	values := make([][]int, len(patches))
+	for i, data := range patches {
+		values[i] = parameterValues(data, 0)
+		for _, alias := range registered {
+			value, _ := getParameter(data, alias.Name)
+			values[i] = append(values[i], value)
+		}
+	}
+	for _, alias := range registered {
+		parameters = append(parameters, Parameter{Path: alias.Name})
+	}
+	known := make(map[string][]int)
+	for i, parameter := range parameters {
+		if !isSpare(parameter.Path) {
+			known[parameter.Path] = column(values, i)
+		}
+	}
+
+	for i, parameter := range parameters {
+		if !isSpare(parameter.Path) {
+			continue
+		}
+		for bit := 0; bit < fieldWidth(root.Type(), parameter.Path); bit++ {
+			if aliased(registered, parameter.Path, bit) {
+				continue
+			}
+			spare := SpareBit{Field: parameter.Path, Bit: bit, Patches: len(patches)}
+			bits := column(values, i)
+			for patch := range bits {
+				bits[patch] = bits[patch] >> uint(bit) & 1
+				spare.Set += bits[patch]
+			}
+			if spare.Set == 0 || spare.Set == len(patches) {
+				continue
+			}
+
+			for path, values := range known {
+				if r, ok := correlation(bits, values); ok && math.Abs(r) >= minimum {
+					spare.Correlations = append(spare.Correlations, Correlation{path, r})
+				}
+			}
+			sort.Slice(spare.Correlations, func(a, b int) bool {
+				ca, cb := spare.Correlations[a], spare.Correlations[b]
+				if math.Abs(ca.Coefficient) != math.Abs(cb.Coefficient) {
+					return math.Abs(ca.Coefficient) > math.Abs(cb.Coefficient)
+				}
+				return ca.Parameter < cb.Parameter
+			})
+			result = append(result, spare)
+		}
+	}
+	return result
+}
+
+// helpers
+
+// The data of each of the library's patches of the type.
+func (library *PatchLibrary) patchData(pt PatchType) []reflect.Value {
+	var result []reflect.Value
+	if pt == PerformanceT {
+		for _, performance := range library.performances {
+			result = append(result, reflect.ValueOf(performance.data).Elem())
+		}
+	} else {
+		for _, program := range library.programs {
+			result = append(result, reflect.ValueOf(program.data).Elem())
+		}
+	}
+	return result
+}
+
+// Spare fields and the padding after slot programs, whose meaning is unknown.
+func isSpare(path string) bool {
+	name := path[strings.LastIndex(path, ".")+1:]
+	return strings.HasPrefix(name, "Spare") || strings.HasPrefix(name, "Patch_pad")
+}
+
+// The aliases applying within rv, including those of the structs nested in it, with names and paths
+// given in full from rv, e.g. "Patch_data_b.Midi_clock_source" in "Patch_data_b.Spare7".
+func nestedAliases(rv reflect.Value, prefix string) []FieldAlias {
+	aliases.RLock()
+	var result []FieldAlias
+	for _, alias := range aliases.byType[rv.Type()] {
+		alias.Name, alias.Path = prefix+alias.Name, prefix+alias.Path
+		result = append(result, alias)
+	}
+	aliases.RUnlock()
+
+	for i := 0; i < rv.NumField(); i++ {
+		if rf := rv.Field(i); rf.Kind() == reflect.Struct {
+			result = append(result, nestedAliases(rf, prefix+rv.Type().Field(i).Name+".")...)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// The values of one parameter across the patches.
+func column(values [][]int, parameter int) []int {
+	result := make([]int, len(values))
+	for patch := range values {
+		result[patch] = values[patch][parameter]
+	}
+	return result
+}
+
+func aliased(registered []FieldAlias, path string, bit int) bool {
+	for _, alias := range registered {
+		if alias.Path == path && bit >= alias.Shift && bit < alias.Shift+alias.Bits {
+			return true
+		}
+	}
+	return false
+}
+
+// The Pearson correlation of the two series, unless either of them never varies.
+func correlation(x []int, y []int) (float64, bool) {
+	n := float64(len(x))
+	var sumX, sumY, sumXX, sumYY, sumXY float64
+	for i := range x {
+		a, b := float64(x[i]), float64(y[i])
+		sumX += a
+		sumY += b
+		sumXX += a * a
+		sumYY += b * b
+		sumXY += a * b
+	}
+	varX, varY := n*sumXX-sumX*sumX, n*sumYY-sumY*sumY
+	if varX == 0 || varY == 0 {
+		return 0, false
+	}
+	return (n*sumXY - sumX*sumY) / math.Sqrt(varX*varY), true
+}
@@ -0,0 +1,85 @@
+package nordlead3
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeSpares(t *testing.T) {
+	programs := make([]*Program, 4)
+	for i := range programs {
+		programs[i] = NewInitProgram()
+		if i%2 == 1 {
+			programs[i].Set("Arpeggio_run", 1)
+			programs[i].Set("Spare3", 1)
+		}
+	}
+	analyze := func() []SpareBit {
+		library := new(PatchLibrary)
+		for _, program := range programs {
+			library.AddProgram(program)
+		}
+		return library.AnalyzeSpares(ProgramT, 0.5)
+	}
+
+	spares := analyze()
+	if len(spares) != 1 || spares[0].Field != "Spare3" || spares[0].Bit != 0 || spares[0].Set != 2 || spares[0].Patches != 4 {
+		t.Fatalf("Expected only bit 0 of Spare3 to vary, got %v", spares)
+	}
+	correlations := spares[0].Correlations
+	if len(correlations) != 1 || correlations[0].Parameter != "Arpeggio_run" || math.Abs(correlations[0].Coefficient-1) > 1e-9 {
+		t.Errorf("Expected the bit to follow Arpeggio_run, got %v", correlations)
+	}
+
+	if err := RegisterAlias(ProgramT, FieldAlias{Name: "Arpeggio_shadow", Path: "Spare3", Bits: 1}); err != nil {
+		t.Fatal(err)
+	}
+	defer RemoveAlias(ProgramT, "Arpeggio_shadow")
+	if spares := analyze(); spares != nil {
+		t.Errorf("Expected an aliased bit to be known, got %v", spares)
+	}
+
+	programs[0].Set("Spare3", 2)
+	spares = analyze()
+	if len(spares) != 1 || spares[0].Bit != 1 || len(spares[0].Correlations) != 2 || spares[0].Correlations[0].Parameter != "Arpeggio_run" || spares[0].Correlations[1].Parameter != "Arpeggio_shadow" {
+		t.Errorf("Expected bit 1 to be compared with the alias as well, got %v", spares)
+	}
+}
+
+func TestAnalyzeSparesOfDumps(t *testing.T) {
+	// PerfBank1.syx dumps locations which AllPerformances.syx holds too
+	library := populatedLibrary(t, "AllPerformances.syx", "PerfBank1.syx")
+
+	spares := library.AnalyzeSpares(PerformanceT, 0.8)
+	if len(spares) == 0 {
+		t.Fatalf("Expected the factory performances to use some spare bits")
+	}
+	nested := 0
+	for _, spare := range spares {
+		if spare.Set == 0 || spare.Set == spare.Patches || spare.Patches != library.NumPerformances() || !isSpare(spare.Field) {
+			t.Errorf("Expected only spare bits that vary, got %v", spare)
+		}
+		if strings.HasPrefix(spare.Field, "Patch_data_") {
+			nested++
+		}
+		for _, correlation := range spare.Correlations {
+			if math.Abs(correlation.Coefficient) < 0.8 || math.Abs(correlation.Coefficient) > 1+1e-9 {
+				t.Errorf("%v: correlation out of bounds", spare)
+			}
+		}
+	}
+	if nested == 0 {
+		t.Errorf("Expected the spare bits of the slot programs to be analyzed")
+	}
+
+	if err := RegisterAlias(ProgramT, FieldAlias{Name: "Spare_shadow", Path: "Spare10"}); err != nil {
+		t.Fatal(err)
+	}
+	defer RemoveAlias(ProgramT, "Spare_shadow")
+	for _, spare := range library.AnalyzeSpares(PerformanceT, 0.8) {
+		if strings.HasSuffix(spare.Field, ".Spare10") {
+			t.Errorf("Expected a program alias to cover the slot programs, got %v", spare)
+		}
+	}
+}
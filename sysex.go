@@ -2,7 +2,6 @@ package nordlead3
 
 import (
 	"bytes"
-	"errors"
 	"fmt"
 	"io"
 	"strings"
@@ -17,6 +16,8 @@ const (
 	modelNL3   = 0x09
 )
 
+// The dump types. There are no dump requests: no message type for them is documented for the NL3, so
+// patches are pulled by dumping them from the unit's panel while PatchMemory.Receive listens.
 const (
 	programFromSlot       = 0x20
 	programFromMemory     = 0x21
@@ -45,7 +46,7 @@ type sysex struct {
 
 type sysexable interface {
 	sysexData() (*[]byte, error)
-	sysexType() uint8
+	sysexType(SourceType) uint8
 	sysexName() []byte
 	sysexCategory() uint8
 	sysexVersion() []byte
@@ -135,36 +136,26 @@ func (s *sysex) sourceType() SourceType {
 }
 
 func (s *sysex) valid() (bool, error) {
-	var errStrs []string
-
 	// Verify message type and expected length
+	expectedLength := 0
 	switch s.messageType() {
 	case programFromSlot, programFromMemory:
-		if len(s.decodedBitstream) != programBitstreamLength {
-			errStrs = append(errStrs, fmt.Sprintf("Error parsing %s (%v:%03d %q): data invalid!", s.printableType(), s.bank(), s.location(), s.printableName()))
-		}
+		expectedLength = programBitstreamLength
 	case performanceFromSlot, performanceFromMemory:
-		if len(s.decodedBitstream) != performanceBitstreamLength {
-			errStrs = append(errStrs, fmt.Sprintf("Error parsing %s (%v:%03d %q): data invalid!", s.printableType(), s.bank(), s.location(), s.printableName()))
-		}
+		expectedLength = performanceBitstreamLength
 	default:
-		errStrs = append(errStrs, fmt.Sprintf("Unknown type %x (%d)", s.messageType(), s.messageType()))
+		return false, ErrUnknownDumpType
+	}
+	if len(s.decodedBitstream) != expectedLength {
+		return false, ErrWrongLength
 	}
 
 	// Compute and validate 8-bit checksum
-	checksum := s.decodedBitstream[len(s.decodedBitstream)-1]
 	payload := s.decodedBitstream[:len(s.decodedBitstream)-1]
-	calculatedChecksum := checksum8(payload)
-	if checksum != calculatedChecksum {
-		errStrs = append(errStrs, fmt.Sprintf("Checksum mismatch parsing %s (%v:%03d %q): expected %x, got %x", s.printableType(), s.bank(), s.location(), s.printableName(), checksum, calculatedChecksum))
-	}
-
-	// Handle return values
-	if len(errStrs) == 0 {
-		return true, nil
-	} else {
-		return false, errors.New(strings.Join(errStrs, " "))
+	if s.checksum() != checksum8(payload) {
+		return false, ErrChecksumMismatch
 	}
+	return true, nil
 }
 
 func (s *sysex) version() float64 {
@@ -180,6 +171,10 @@ func parseSysex(rawSysex []byte) (*sysex, error) {
 		rawSysex = rawSysex[:len(rawSysex)-1]
 	}
 
+	if len(rawSysex) <= patchdataOffset {
+		return nil, ErrNotADump
+	}
+
 	s := sysex{rawSysex: rawSysex}
 	s.decodeBitstream()
 
@@ -256,7 +251,7 @@ func toSysex(obj sysexable, ref patchRef) (*[]byte, error) {
 	buffer := bytes.NewBuffer(nil)
 
 	buffer.Write(sysexHeader)
-	buffer.Write([]byte{obj.sysexType(), uint8(ref.bank()), uint8(ref.location())})
+	buffer.Write([]byte{obj.sysexType(ref.source), uint8(ref.bank()), uint8(ref.location())})
 	buffer.Write(obj.sysexName())
 	buffer.WriteByte(obj.sysexCategory())
 	buffer.Write((*new([spareHeaderLength]byte))[:])
@@ -279,9 +274,9 @@ func splitSysex(vendor, model uint8) func(data []byte, atEOF bool) (advance int,
 	header := []byte{sysexStart, vendor}
 	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
 		if si := bytes.Index(data, header); si >= 0 {
-			if bytes.IndexByte(data[si:si+4], model) == si+3 {
-				if ei := bytes.Index(data, []byte{sysexEnd}); ei >= 0 {
-					return ei + 1, data[si : ei+1], nil
+			if si+4 <= len(data) && bytes.IndexByte(data[si:si+4], model) == 3 {
+				if ei := bytes.IndexByte(data[si:], sysexEnd); ei >= 0 {
+					return si + ei + 1, data[si : si+ei+1], nil
 				}
 			}
 		}
@@ -0,0 +1,92 @@
+package nordlead3
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestInitProgramsRoundTrip(t *testing.T) {
+	for _, program := range []*Program{NewInitProgram(), NewInitFMProgram()} {
+		memory := new(PatchMemory)
+		ml := MemoryLocation{0, 0}
+		memory.set(patchRef{ProgramT, MemoryT, ml.index()}, program)
+
+		var buf bytes.Buffer
+		if err := memory.ExportProgram(ml, &buf); err != nil {
+			t.Fatalf("Unexpected error exporting %q: %s", program.PrintableName(), err)
+		}
+		if _, err := parseSysex(buf.Bytes()); err != nil {
+			t.Errorf("Exported %q is not valid sysex: %s", program.PrintableName(), err)
+		}
+
+		reloaded := new(PatchMemory)
+		helperLoadFromSysex(t, reloaded, buf.Bytes())
+		loaded, err := reloaded.GetProgram(ml)
+		if err != nil {
+			t.Fatalf("Could not reload %q: %s", program.PrintableName(), err)
+		}
+		if loaded.Summary() != program.Summary() || !reflect.DeepEqual(loaded.data, program.data) {
+			t.Errorf("%q did not round-trip", program.PrintableName())
+		}
+		if program.data.Arpeggiator_clocksync || program.data.Lfo1_clocksync || program.data.Lfo2_clocksync {
+			t.Errorf("%q should not be clock synced", program.PrintableName())
+		}
+	}
+}
+
+func TestInitProgramFollowsUnit(t *testing.T) {
+	var copies [][]int
+	memory := populatedMemory(t, "AllPerformances.syx")
+	for _, ref := range memory.initializedRefs(PerformanceT) {
+		performance := memory.at(ref).(*Performance)
+		for slot := 0; slot < NumSlots; slot++ {
+			if view := performance.data.Slot(slot); nameToString([16]byte(*view.Name)) == "Init sound" {
+				copies = append(copies, parameterValues(reflect.ValueOf(view.Data).Elem(), 0))
+			}
+		}
+	}
+	if len(copies) == 0 {
+		t.Fatal("Expected the performances to hold copies of the unit's init sound")
+	}
+
+	init := reflect.ValueOf(NewInitProgram().data).Elem()
+	values := parameterValues(init, 0)
+	for i, parameter := range listParameters(init, "", 0) {
+		counts := make(map[int]int)
+		for _, copy := range copies {
+			counts[copy[i]]++
+		}
+		for value, count := range counts {
+			if count*4 >= len(copies)*3 && value != values[i] {
+				t.Errorf("%s is %d, but %d of %d copies of the unit's init sound have %d", parameter.Path, values[i], count, len(copies), value)
+			}
+		}
+	}
+}
+
+func TestInitPerformanceRoundTrip(t *testing.T) {
+	performance := NewInitPerformance()
+	memory := new(PatchMemory)
+	ml := MemoryLocation{0, 0}
+	memory.set(patchRef{PerformanceT, MemoryT, ml.index()}, performance)
+
+	var buf bytes.Buffer
+	if err := memory.ExportPerformance(ml, &buf); err != nil {
+		t.Fatalf("Unexpected error exporting init performance: %s", err)
+	}
+	reloaded := new(PatchMemory)
+	helperLoadFromSysex(t, reloaded, buf.Bytes())
+	loaded, err := reloaded.GetPerformance(ml)
+	if err != nil {
+		t.Fatalf("Could not reload init performance: %s", err)
+	}
+	if !reflect.DeepEqual(loaded.data, performance.data) {
+		t.Errorf("Init performance did not round-trip")
+	}
+	expected := *NewInitProgram().data
+	expected.Version_number = 0
+	if !reflect.DeepEqual(performance.data.Patch_data_d, expected) {
+		t.Errorf("Slots should hold the init program")
+	}
+}
@@ -111,12 +111,12 @@ func helperLoadFromFile(t *testing.T, memory *PatchMemory, filename string) {
 	if err != nil {
 		t.Fatal(fmt.Printf("Could not open %q: %q\n", filename, err))
 	}
-	memory.Import(file, true)
+	memory.Import(file, ConflictOverwrite)
 }
 
 func helperLoadFromSysex(t *testing.T, memory *PatchMemory, sysex []byte) {
 	r := bytes.NewReader(sysex)
-	_, _, err := memory.Import(r, true)
+	_, err := memory.Import(r, ConflictOverwrite)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -128,6 +128,22 @@ func populatedMemory(t *testing.T, filename string) *PatchMemory {
 	return memory
 }
 
+func populatedLibrary(t *testing.T, filenames ...string) *PatchLibrary {
+	library := new(PatchLibrary)
+	for _, filename := range filenames {
+		file, err := os.Open(filepath.Join("testdata", filename))
+		if err != nil {
+			t.Fatalf("Could not open %q: %q\n", filename, err)
+		}
+		_, _, err = library.Import(file)
+		file.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return library
+}
+
 func tailBytes(buf []byte, n int) []byte {
 	start := max(0, len(buf)-n)
 	return buf[start:]
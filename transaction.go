@@ -0,0 +1,79 @@
+package nordlead3
+
+import (
+	"io"
+)
+
+// The edits available within a Transaction. A Tx is only valid until the function it was passed to returns.
+type Tx struct {
+	memory *PatchMemory
+}
+
+// Runs edit as a single operation: if edit returns an error, every change it made is put back and memory is
+// left untouched; otherwise all of them are kept, and can be undone together. The error is returned as is.
+// Memory should not be edited other than through tx until edit returns.
+func (memory *PatchMemory) Transaction(edit func(tx *Tx) error) error {
+	return memory.atomically("transaction", func() error {
+		return edit(&Tx{memory})
+	})
+}
+
+func (tx *Tx) CopyPerformance(src MemoryLocation, dest MemoryLocation) error {
+	return tx.memory.copy(patchRef{PerformanceT, MemoryT, src.index()}, patchRef{PerformanceT, MemoryT, dest.index()})
+}
+
+func (tx *Tx) CopyProgram(src MemoryLocation, dest MemoryLocation) error {
+	return tx.memory.copy(patchRef{ProgramT, MemoryT, src.index()}, patchRef{ProgramT, MemoryT, dest.index()})
+}
+
+// Unlike PatchMemory.DeletePerformance, returns ErrUninitialized if there is nothing at ml to delete.
+func (tx *Tx) DeletePerformance(ml MemoryLocation) error {
+	return tx.delete(patchRef{PerformanceT, MemoryT, ml.index()})
+}
+
+// Unlike PatchMemory.DeleteProgram, returns ErrUninitialized if there is nothing at ml to delete.
+func (tx *Tx) DeleteProgram(ml MemoryLocation) error {
+	return tx.delete(patchRef{ProgramT, MemoryT, ml.index()})
+}
+
+func (tx *Tx) Import(input io.Reader, policy ConflictPolicy) (*ImportReport, error) {
+	return tx.memory.Import(input, policy)
+}
+
+func (tx *Tx) ImportTo(input io.Reader, pt PatchType, ml MemoryLocation, policy ConflictPolicy) (*ImportReport, error) {
+	return tx.memory.ImportTo(input, pt, ml, policy)
+}
+
+func (tx *Tx) MovePerformances(src []MemoryLocation, dest MemoryLocation, policy ConflictPolicy) error {
+	return tx.memory.MovePerformances(src, dest, policy)
+}
+
+func (tx *Tx) MovePrograms(src []MemoryLocation, dest MemoryLocation, policy ConflictPolicy) error {
+	return tx.memory.MovePrograms(src, dest, policy)
+}
+
+func (tx *Tx) SetCategory(pt PatchType, ml MemoryLocation, category int) error {
+	return tx.memory.SetCategory(pt, ml, category)
+}
+
+func (tx *Tx) SetName(pt PatchType, ml MemoryLocation, name string) error {
+	return tx.memory.SetName(pt, ml, name)
+}
+
+func (tx *Tx) SwapPerformances(a MemoryLocation, b MemoryLocation) error {
+	return tx.memory.SwapPerformances(a, b)
+}
+
+func (tx *Tx) SwapPrograms(a MemoryLocation, b MemoryLocation) error {
+	return tx.memory.SwapPrograms(a, b)
+}
+
+// helpers
+
+func (tx *Tx) delete(ref patchRef) error {
+	if _, err := tx.memory.get(ref); err != nil {
+		return err
+	}
+	tx.memory.clear(ref)
+	return nil
+}
@@ -0,0 +1,104 @@
+package nordlead3
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTransactionCommits(t *testing.T) {
+	memory := populatedMemory(t, "Program-BladeRun     ZON-1.18.syx")
+	at := MemoryLocation{validProgramBank, validProgramLocation}
+
+	err := memory.Transaction(func(tx *Tx) error {
+		if err := tx.CopyProgram(at, MemoryLocation{0, 0}); err != nil {
+			return err
+		}
+		if err := tx.SetName(ProgramT, MemoryLocation{0, 0}, "Copy"); err != nil {
+			return err
+		}
+		if err := tx.MovePrograms([]MemoryLocation{at}, MemoryLocation{0, 1}, ConflictAbort); err != nil {
+			return err
+		}
+		_, err := tx.Import(bytes.NewReader(validPerformanceSysex(t)), ConflictAbort)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if memory.NumPrograms(true) != 2 || memory.NumPerformances(true) != 1 || memory.initialized(validProgramRef) {
+		t.Errorf("Expected the transaction to be committed")
+	}
+
+	if description, _ := memory.Undo(); description != "transaction" || memory.NumPrograms(true) != 1 || !memory.initialized(validProgramRef) || memory.NumPerformances(true) != 0 {
+		t.Errorf("Expected a single undo to revert the whole transaction, undid %q", description)
+	}
+}
+
+func TestTransactionRollsBack(t *testing.T) {
+	memory := populatedMemory(t, "Program-BladeRun     ZON-1.18.syx")
+	at := MemoryLocation{validProgramBank, validProgramLocation}
+	original, _ := memory.GetProgram(at)
+
+	err := memory.Transaction(func(tx *Tx) error {
+		tx.SetName(ProgramT, at, "Renamed")
+		tx.SwapPrograms(at, MemoryLocation{7, 127})
+		tx.CopyProgram(MemoryLocation{7, 127}, MemoryLocation{0, 0})
+		tx.Import(bytes.NewReader(validPerformanceSysex(t)), ConflictAbort)
+		return tx.DeleteProgram(MemoryLocation{1, 1})
+	})
+	if err != ErrUninitialized {
+		t.Errorf("Expected the error from the transaction, got %v", err)
+	}
+
+	if program, _ := memory.GetProgram(at); program != original || program.PrintableName() != validProgramName {
+		t.Errorf("Expected the original program to be put back, got %v", program)
+	}
+	if memory.NumPrograms(true) != 1 || memory.NumPerformances(true) != 0 {
+		t.Errorf("Expected everything the transaction did to be put back")
+	}
+	if description, _ := memory.Undo(); description != "import" {
+		t.Errorf("Expected the rolled back transaction not to be recorded, undid %q", description)
+	}
+}
+
+func TestFailedMoveWithinTransaction(t *testing.T) {
+	memory := populatedMemory(t, "Program-BladeRun     ZON-1.18.syx")
+	at := MemoryLocation{validProgramBank, validProgramLocation}
+
+	memory.Transaction(func(tx *Tx) error {
+		tx.CopyProgram(at, MemoryLocation{0, 0})
+		tx.CopyProgram(at, MemoryLocation{0, 2})
+		if err := tx.MovePrograms([]MemoryLocation{{0, 0}, at}, MemoryLocation{0, 1}, ConflictAbort); err != ErrMemoryOccupied {
+			t.Errorf("Expected ErrMemoryOccupied, got %v", err)
+		}
+		return nil
+	})
+	if memory.NumPrograms(true) != 3 || !memory.initialized(patchRef{ProgramT, MemoryT, 0}) {
+		t.Errorf("Expected only the failed move to be put back")
+	}
+}
+
+func TestPanickingTransaction(t *testing.T) {
+	memory := populatedMemory(t, "Program-BladeRun     ZON-1.18.syx")
+	at := MemoryLocation{validProgramBank, validProgramLocation}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Expected the panic to reach the caller")
+			}
+		}()
+		memory.Transaction(func(tx *Tx) error {
+			tx.CopyProgram(at, MemoryLocation{0, 0})
+			panic("edit failed")
+		})
+	}()
+	if memory.NumPrograms(true) != 1 {
+		t.Errorf("Expected the changes made before the panic to be put back")
+	}
+
+	memory.SetName(ProgramT, at, "Renamed")
+	if description, _ := memory.Undo(); description != "rename program 3:004" || memory.programs[validProgramRef.index].PrintableName() != validProgramName {
+		t.Errorf("Expected operations after the panic to be undoable, undid %q", description)
+	}
+}
@@ -0,0 +1,265 @@
+package nordlead3
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"sync"
+	"time"
+)
+
+// A Transport carries MIDI messages to and from a connected unit. Every message is complete: sysex
+// including its F0/F7 terminators, or a channel message including its status byte. Realtime messages
+// (clock, active sensing...) are dropped on receipt, and running status is expanded.
+type Transport interface {
+	Send(message []byte) error
+	Receive(timeout time.Duration) ([]byte, error)
+	Close() error
+}
+
+// Returns two transports connected to each other, for tests and for tools which talk to an emulated unit.
+// As with a real port, sending blocks once the other end has a backlog of messages it has not received.
+func NewLoopback() (Transport, Transport) {
+	aReader, bWriter := io.Pipe()
+	bReader, aWriter := io.Pipe()
+	return newStreamTransport(&pipePort{aReader, aWriter}), newStreamTransport(&pipePort{bReader, bWriter})
+}
+
+// Sends the performance at ml to the memory location dest of the unit.
+func (memory *PatchMemory) SendPerformance(t Transport, ml MemoryLocation, dest MemoryLocation) error {
+	return memory.send(t, patchRef{PerformanceT, MemoryT, ml.index()}, patchRef{PerformanceT, MemoryT, dest.index()})
+}
+
+// Sends the performance at ml to the performance slot of the unit, where it can be auditioned without being stored.
+func (memory *PatchMemory) SendPerformanceToSlot(t Transport, ml MemoryLocation) error {
+	return memory.send(t, patchRef{PerformanceT, MemoryT, ml.index()}, performanceSlotRef)
+}
+
+// Sends the program at ml to the memory location dest of the unit.
+func (memory *PatchMemory) SendProgram(t Transport, ml MemoryLocation, dest MemoryLocation) error {
+	return memory.send(t, patchRef{ProgramT, MemoryT, ml.index()}, patchRef{ProgramT, MemoryT, dest.index()})
+}
+
+// Sends the program at ml to one of the program slots of the unit, where it can be auditioned without being stored.
+func (memory *PatchMemory) SendProgramToSlot(t Transport, ml MemoryLocation, slot int) error {
+	return memory.send(t, patchRef{ProgramT, MemoryT, ml.index()}, patchRef{ProgramT, SlotT, slot})
+}
+
+// Imports the dumps sent by the unit, preserving the locations they were dumped from, until no message
+// arrives within timeout. Messages which are not NL3 dumps are ignored, though their bytes count towards
+// the offsets in the report.
+func (memory *PatchMemory) Receive(t Transport, timeout time.Duration, policy ConflictPolicy) (*ImportReport, error) {
+	report := new(ImportReport)
+	var offset int64
+
+	err := memory.record("receive", func() error {
+		for {
+			message, err := t.Receive(timeout)
+			if err == ErrTimeout {
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			received, err := memory.Import(bytes.NewReader(message), policy)
+			report.append(received, offset)
+			offset += int64(len(message))
+			if err != nil {
+				return err
+			}
+		}
+	})
+	return report, err
+}
+
+// helpers
+
+func (memory *PatchMemory) send(t Transport, src patchRef, dest patchRef) error {
+	if !dest.valid() {
+		return ErrInvalidLocation
+	}
+	patch, err := memory.get(src)
+	if err != nil {
+		return err
+	}
+	message, err := toSysex(patch.(sysexable), dest)
+	if err != nil {
+		return err
+	}
+	return t.Send(*message)
+}
+
+// A Transport over any byte stream carrying raw MIDI, such as a device file.
+type streamTransport struct {
+	port      io.ReadWriteCloser
+	messages  chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+	err       error // reason the stream ended, valid once messages is closed
+}
+
+func newStreamTransport(port io.ReadWriteCloser) *streamTransport {
+	t := &streamTransport{
+		port:     port,
+		messages: make(chan []byte, 64),
+		done:     make(chan struct{}),
+	}
+	go t.read()
+	return t
+}
+
+func (t *streamTransport) Send(message []byte) error {
+	select {
+	case <-t.done:
+		return ErrTransportClosed
+	default:
+	}
+	_, err := t.port.Write(message)
+	return err
+}
+
+func (t *streamTransport) Receive(timeout time.Duration) ([]byte, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case message, ok := <-t.messages:
+		if !ok {
+			if t.err != nil {
+				return nil, t.err
+			}
+			return nil, ErrTransportClosed
+		}
+		return message, nil
+	case <-timer.C:
+		return nil, ErrTimeout
+	}
+}
+
+func (t *streamTransport) Close() error {
+	err := ErrTransportClosed
+	t.closeOnce.Do(func() {
+		close(t.done)
+		err = t.port.Close()
+	})
+	return err
+}
+
+func (t *streamTransport) read() {
+	defer close(t.messages)
+
+	scanner := bufio.NewScanner(t.port)
+	scanner.Split(splitMIDI())
+
+	for scanner.Scan() {
+		message := append([]byte(nil), scanner.Bytes()...)
+		select {
+		case t.messages <- message:
+		case <-t.done:
+			return
+		}
+	}
+	t.err = scanner.Err()
+}
+
+// One end of a loopback: reads what the other end writes.
+type pipePort struct {
+	reader *io.PipeReader
+	writer *io.PipeWriter
+}
+
+func (p *pipePort) Read(data []byte) (int, error) {
+	return p.reader.Read(data)
+}
+
+func (p *pipePort) Write(data []byte) (int, error) {
+	return p.writer.Write(data)
+}
+
+func (p *pipePort) Close() error {
+	p.reader.Close()
+	return p.writer.Close()
+}
+
+// A bufio.Scanner split function which cuts a raw MIDI stream into complete messages. Realtime bytes are
+// dropped wherever they appear, running status is expanded, and data bytes without a status are skipped.
+func splitMIDI() bufio.SplitFunc {
+	var runningStatus byte
+
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		for advance < len(data) {
+			status, start := data[advance], advance+1
+
+			switch {
+			case status >= 0xF8: // realtime
+				advance++
+				continue
+			case status&0x80 == 0:
+				if runningStatus == 0 {
+					advance++
+					continue
+				}
+				status, start = runningStatus, advance
+			case status != sysexStart && messageLength(status) == 0: // stray sysexEnd or undefined
+				runningStatus = 0
+				advance++
+				continue
+			}
+
+			message, end, complete := readMessage(data, status, start)
+			if !complete {
+				return advance, nil, nil // wait for the rest
+			}
+			advance = end
+			if message == nil {
+				continue // cut short by another status byte
+			}
+
+			if status < 0xF0 {
+				runningStatus = status
+			} else {
+				runningStatus = 0
+			}
+			return advance, message, nil
+		}
+		return advance, nil, nil
+	}
+}
+
+// Collects the bytes of a message, skipping interleaved realtime bytes. If another status byte interrupts
+// the message, a nil message is returned along with the position of that byte.
+func readMessage(data []byte, status byte, start int) (message []byte, end int, complete bool) {
+	message = []byte{status}
+
+	for i := start; ; i++ {
+		if status != sysexStart && len(message) == messageLength(status) {
+			return message, i, true
+		}
+		if i >= len(data) {
+			return nil, 0, false
+		}
+
+		switch b := data[i]; {
+		case b >= 0xF8:
+		case b == sysexEnd && status == sysexStart:
+			return append(message, b), i + 1, true
+		case b&0x80 != 0:
+			return nil, i, true
+		default:
+			message = append(message, b)
+		}
+	}
+}
+
+// Length in bytes of the MIDI message starting with the given status byte, or 0 for sysex and undefined statuses.
+func messageLength(status byte) int {
+	switch {
+	case status >= 0x80 && status < 0xC0, status >= 0xE0 && status < 0xF0, status == 0xF2:
+		return 3
+	case status >= 0xC0 && status < 0xE0, status == 0xF1, status == 0xF3:
+		return 2
+	case status == 0xF6:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,20 @@
+package nordlead3
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Opens an ALSA raw MIDI device, e.g. /dev/snd/midiC1D0 for the first port of the second sound card.
+func OpenRawMIDI(path string) (Transport, error) {
+	port, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	return newStreamTransport(port), nil
+}
+
+// Lists the ALSA raw MIDI devices present on the system.
+func RawMIDIDevices() ([]string, error) {
+	return filepath.Glob("/dev/snd/midiC*D*")
+}
@@ -0,0 +1,116 @@
+package nordlead3
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+)
+
+const testTimeout = 100 * time.Millisecond
+
+func TestSplitMIDI(t *testing.T) {
+	stream := []byte{
+		0x05,             // data without status: dropped
+		0x90, 0x3C, 0x64, // note on
+		0x3E, 0xF8, 0x64, // running status, clock in between
+		0xF0, 0x33, 0xFE, 0x7F, 0xF7, // sysex with active sensing inside
+		0xC1, 0x05, // program change
+		0xB0, 0x07, 0xF6, // control change cut short by tune request
+	}
+	expected := [][]byte{
+		{0x90, 0x3C, 0x64},
+		{0x90, 0x3E, 0x64},
+		{0xF0, 0x33, 0x7F, 0xF7},
+		{0xC1, 0x05},
+		{0xF6},
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(stream))
+	scanner.Split(splitMIDI())
+
+	i := 0
+	for ; scanner.Scan(); i++ {
+		if i >= len(expected) {
+			t.Fatalf("Unexpected message % x", scanner.Bytes())
+		}
+		if !bytes.Equal(scanner.Bytes(), expected[i]) {
+			t.Errorf("Message %d: expected % x, got % x", i, expected[i], scanner.Bytes())
+		}
+	}
+	if i != len(expected) {
+		t.Errorf("Expected %d messages, got %d", len(expected), i)
+	}
+}
+
+func TestSendAndReceive(t *testing.T) {
+	memory := populatedMemory(t, "AllPrograms.syx")
+	receiver := new(PatchMemory)
+	unit, editor := NewLoopback()
+	defer unit.Close()
+	defer editor.Close()
+
+	src := MemoryLocation{validProgramBank, validProgramLocation}
+	dest := MemoryLocation{0, 7}
+
+	if err := memory.SendProgram(editor, src, dest); err != nil {
+		t.Fatal(err)
+	}
+	report, err := receiver.Receive(unit, testTimeout, ConflictSkip)
+	if err != nil || report.NumImported() != 1 || report.NumRejected() != 0 {
+		t.Fatalf("Expected 1 valid program, got %d valid, %d invalid (%v)", report.NumImported(), report.NumRejected(), err)
+	}
+
+	sent, _ := memory.GetProgram(src)
+	received, err := receiver.GetProgram(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if received.PrintableName() != sent.PrintableName() || *received.data != *sent.data {
+		t.Errorf("Received %q does not match sent %q", received.PrintableName(), sent.PrintableName())
+	}
+}
+
+func TestSendToSlot(t *testing.T) {
+	memory := populatedMemory(t, "AllPrograms.syx")
+	unit, editor := NewLoopback()
+	defer unit.Close()
+	defer editor.Close()
+
+	if err := memory.SendProgramToSlot(editor, MemoryLocation{validProgramBank, validProgramLocation}, 2); err != nil {
+		t.Fatal(err)
+	}
+	message, err := unit.Receive(testTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header := message[4:7]; !bytes.Equal(header, []byte{programFromSlot, 0, 2}) {
+		t.Errorf("Expected a dump to slot 2, got header % x", header)
+	}
+
+	if err := memory.SendProgramToSlot(editor, MemoryLocation{validProgramBank, validProgramLocation}, 4); err != ErrInvalidLocation {
+		t.Errorf("Expected ErrInvalidLocation sending to slot 4, got %v", err)
+	}
+}
+
+func TestReceiveIgnoresChannelMessages(t *testing.T) {
+	memory := new(PatchMemory)
+	unit, editor := NewLoopback()
+	defer editor.Close()
+	sysex := validPerformanceSysex(t)
+
+	go func() {
+		unit.Send([]byte{0xC0, 0x01})
+		unit.Send(sysex)
+		unit.Close()
+	}()
+
+	report, err := memory.Receive(editor, testTimeout, ConflictSkip)
+	if err != ErrTransportClosed {
+		t.Errorf("Expected ErrTransportClosed once the unit goes away, got %v", err)
+	}
+	if report.NumImported() != 1 || report.Entries[0].Offset != 2 {
+		t.Errorf("Expected 1 valid performance after the program change, got %s", report)
+	}
+	requireInitialized(t, memory, validPerformanceRef)
+}
@@ -0,0 +1,138 @@
+package nordlead3
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// rangePolicies: how fields holding values outside their min/max tags are handled on import
+const (
+	RangeIgnore RangePolicy = iota // keep the values as dumped, so that they round-trip unchanged
+	RangeReject                    // refuse the patch
+	RangeClamp                     // move each value to the nearest end of its range
+)
+
+type RangePolicy int
+
+// A field holding a value outside the range given by its min and max tags.
+type RangeError struct {
+	Field string // path of the field, e.g. "Patch_data_a.Wheel_morph_params.Oscmix"
+	Value int
+	Min   int
+	Max   int
+}
+
+type RangeErrors []RangeError
+
+func (e RangeError) Error() string {
+	return fmt.Sprintf("%s is %d, outside %d to %d", e.Field, e.Value, e.Min, e.Max)
+}
+
+func (errs RangeErrors) Error() string {
+	var result []string
+	for _, e := range errs {
+		result = append(result, e.Error())
+	}
+	return strings.Join(result, "; ")
+}
+
+// Lists the fields holding values outside their range, or nil if all of them are in range.
+func (performanceData *PerformanceData) Validate() RangeErrors {
+	return checkRanges(reflect.ValueOf(performanceData).Elem(), "", 0, false)
+}
+
+// Lists the fields holding values outside their range, or nil if all of them are in range.
+func (programData *ProgramData) Validate() RangeErrors {
+	return checkRanges(reflect.ValueOf(programData).Elem(), "", 0, false)
+}
+
+func (memory *PatchMemory) SetRangePolicy(policy RangePolicy) {
+	memory.rangePolicy = policy
+}
+
+// helpers
+
+func (performanceData *PerformanceData) clamp() RangeErrors {
+	return checkRanges(reflect.ValueOf(performanceData).Elem(), "", 0, true)
+}
+
+func (programData *ProgramData) clamp() RangeErrors {
+	return checkRanges(reflect.ValueOf(programData).Elem(), "", 0, true)
+}
+
+// Applies the memory's range policy to freshly decoded data.
+func (memory *PatchMemory) enforceRanges(validate func() RangeErrors, clamp func() RangeErrors) error {
+	switch memory.rangePolicy {
+	case RangeReject:
+		if errs := validate(); errs != nil {
+			return errs
+		}
+	case RangeClamp:
+		clamp()
+	}
+	return nil
+}
+
+// Walks the fields of the struct, descending into embedded structs, and reports (and optionally clamps)
+// every number outside the range given by its tags. Fields without both tags are not checked.
+func checkRanges(rv reflect.Value, prefix string, depth int, clamp bool) RangeErrors {
+	var errs RangeErrors
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		rf := rv.Field(i)
+
+		if skipField(sf, depth) {
+			continue
+		}
+		if rf.Kind() == reflect.Struct {
+			errs = append(errs, checkRanges(rf, prefix+sf.Name+".", depth+1, clamp)...)
+			continue
+		}
+
+		lo, hi, ok := fieldRange(sf)
+		if !ok {
+			continue
+		}
+		var value int
+		switch rf.Kind() {
+		case reflect.Int:
+			value = int(rf.Int())
+		case reflect.Uint:
+			value = int(rf.Uint())
+		default:
+			continue
+		}
+
+		if value < lo || value > hi {
+			errs = append(errs, RangeError{prefix + sf.Name, value, lo, hi})
+			if clamp {
+				setNumber(rf, max(lo, min(value, hi)))
+			}
+		}
+	}
+	return errs
+}
+
+func fieldRange(sf reflect.StructField) (lo int, hi int, ok bool) {
+	strMin, hasMin := sf.Tag.Lookup("min")
+	strMax, hasMax := sf.Tag.Lookup("max")
+	if !hasMin || !hasMax {
+		return 0, 0, false
+	}
+	lo, errMin := strconv.Atoi(strMin)
+	hi, errMax := strconv.Atoi(strMax)
+	return lo, hi, errMin == nil && errMax == nil
+}
+
+func setNumber(rf reflect.Value, value int) {
+	switch rf.Kind() {
+	case reflect.Int:
+		rf.SetInt(int64(value))
+	case reflect.Uint, reflect.Uint8:
+		rf.SetUint(uint64(value))
+	}
+}
@@ -0,0 +1,117 @@
+package nordlead3
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+var wellFormedTag = regexp.MustCompile(`^[a-zA-Z]+:"[^"]*"( [a-zA-Z]+:"[^"]*")*$`)
+
+func TestLintTags(t *testing.T) {
+	for _, data := range []interface{}{ProgramData{}, PerformanceData{}, MorphParams{}} {
+		rt := reflect.TypeOf(data)
+		for i := 0; i < rt.NumField(); i++ {
+			lintField(t, rt.Name(), rt.Field(i))
+		}
+	}
+}
+
+func lintField(t *testing.T, typeName string, sf reflect.StructField) {
+	name := typeName + "." + sf.Name
+
+	if !wellFormedTag.MatchString(string(sf.Tag)) {
+		t.Errorf("%s: malformed tag `%s`", name, sf.Tag)
+		return
+	}
+	length, err := strconv.Atoi(sf.Tag.Get("len"))
+	if err != nil || length <= 0 {
+		t.Errorf("%s: len must be a positive number of bits, got %q", name, sf.Tag.Get("len"))
+		return
+	}
+
+	_, hasMin := sf.Tag.Lookup("min")
+	_, hasMax := sf.Tag.Lookup("max")
+	if hasMin != hasMax {
+		t.Errorf("%s: min and max must be given together", name)
+		return
+	}
+	if !hasMin || sf.Type.Kind() == reflect.Bool {
+		return
+	}
+	lo, hi, ok := fieldRange(sf)
+	if !ok || lo > hi {
+		t.Errorf("%s: invalid range %q to %q", name, sf.Tag.Get("min"), sf.Tag.Get("max"))
+		return
+	}
+
+	var fitsLo, fitsHi int
+	switch sf.Type.Kind() {
+	case reflect.Int:
+		fitsLo, fitsHi = -(1 << uint(length-1)), 1<<uint(length-1)-1
+	case reflect.Uint:
+		fitsLo, fitsHi = 0, 1<<uint(length)-1
+	default:
+		t.Errorf("%s: range given for a %v", name, sf.Type.Kind())
+		return
+	}
+	if lo < fitsLo || hi > fitsHi {
+		t.Errorf("%s: range %d to %d does not fit in %d bits", name, lo, hi, length)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	program := NewInitProgram()
+	if errs := program.data.Validate(); errs != nil {
+		t.Errorf("Init program should be valid, got %v", errs)
+	}
+
+	program.data.Osc1_waveform = 7
+	errs := program.data.Validate()
+	if len(errs) != 1 || errs[0] != (RangeError{"Osc1_waveform", 7, 0, 5}) {
+		t.Errorf("Expected Osc1_waveform to be reported out of range, got %v", errs)
+	}
+
+	performance := NewInitPerformance()
+	performance.data.Patch_data_c.Wheel_morph_params.Oscmix = -129
+	errs = performance.data.Validate()
+	if len(errs) != 1 || errs[0].Field != "Patch_data_c.Wheel_morph_params.Oscmix" {
+		t.Errorf("Expected the slot C wheel morph to be reported out of range, got %v", errs)
+	}
+}
+
+func TestImportRangePolicy(t *testing.T) {
+	source := new(PatchMemory)
+	ref := patchRef{ProgramT, MemoryT, 0}
+	program := NewInitProgram()
+	program.data.Filt1_slope = 3
+	source.set(ref, program)
+	var buf bytes.Buffer
+	if err := source.ExportProgram(MemoryLocation{0, 0}, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		policy        RangePolicy
+		expectedValid int
+		expectedSlope FilterSlope
+	}{
+		{RangeIgnore, 1, 3},
+		{RangeReject, 0, 0},
+		{RangeClamp, 1, 2},
+	}
+
+	for _, c := range cases {
+		memory := new(PatchMemory)
+		memory.SetRangePolicy(c.policy)
+		report, _ := memory.Import(bytes.NewReader(buf.Bytes()), ConflictSkip)
+		if numValid := report.NumImported(); numValid != c.expectedValid {
+			t.Errorf("Policy %d: expected %d valid, got %d", c.policy, c.expectedValid, numValid)
+		}
+		if loaded, err := memory.GetProgram(MemoryLocation{0, 0}); err == nil && loaded.data.Filt1_slope != c.expectedSlope {
+			t.Errorf("Policy %d: expected slope %d, got %d", c.policy, c.expectedSlope, loaded.data.Filt1_slope)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package nordlead3
+
+import (
+	"math"
+)
+
+// The OS versions whose dumps have been checked, oldest to newest. The v1.18 dumps in testdata decode
+// bit-for-bit with the v1.20 field positions and set the same fields, the arpeggiator and LFO clock sync
+// included, so no difference between the two is known and converting only changes the version number.
+var knownVersions = []float64{1.18, 1.20}
+
+// Returns the known version which follows (direction 1) or precedes (direction -1) version.
+func adjacentVersion(version float64, direction int) (float64, error) {
+	for i, known := range knownVersions {
+		if versionX100(known) == versionX100(version) {
+			if i+direction < 0 || i+direction >= len(knownVersions) {
+				return 0, ErrNoVersionConversion
+			}
+			return knownVersions[i+direction], nil
+		}
+	}
+	return 0, ErrUnsupportedVersion
+}
+
+func versionX100(version float64) int {
+	return int(math.Round(version * 100))
+}
@@ -0,0 +1,74 @@
+package nordlead3
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestUpgradeAndDowngradeProgram(t *testing.T) {
+	memory := new(PatchMemory)
+	helperLoadFromSysex(t, memory, validProgramSysex(t))
+	program, _ := memory.GetProgram(MemoryLocation{validProgramBank, validProgramLocation})
+	program.data.Arpeggiator_clocksync = true
+
+	if err := program.Upgrade(); err != nil {
+		t.Fatalf("Unexpected error upgrading program: %s", err)
+	}
+	if program.Version() != 1.20 || program.data.Version_number != 120 {
+		t.Errorf("Expected upgraded version 1.20, got %1.2f (%d)", program.Version(), program.data.Version_number)
+	}
+	if !program.data.Arpeggiator_clocksync {
+		t.Errorf("Upgrade cleared a switch")
+	}
+
+	// The upgraded program must survive a round trip at its new version
+	var buf bytes.Buffer
+	if err := memory.ExportProgram(MemoryLocation{validProgramBank, validProgramLocation}, &buf); err != nil {
+		t.Fatalf("Unexpected error exporting upgraded program: %s", err)
+	}
+	reloaded := new(PatchMemory)
+	helperLoadFromSysex(t, reloaded, buf.Bytes())
+	if p, err := reloaded.GetProgram(MemoryLocation{validProgramBank, validProgramLocation}); err != nil || p.Version() != 1.20 {
+		t.Errorf("Upgraded program did not round-trip at v1.20: %v", err)
+	}
+
+	if err := program.Downgrade(); err != nil {
+		t.Fatalf("Unexpected error downgrading program: %s", err)
+	}
+	if program.Version() != 1.18 || program.data.Version_number != 118 || !program.data.Arpeggiator_clocksync {
+		t.Errorf("Expected downgraded version 1.18 with every field kept, got %1.2f (%d)", program.Version(), program.data.Version_number)
+	}
+	if err := program.Downgrade(); err != ErrNoVersionConversion {
+		t.Errorf("Expected ErrNoVersionConversion downgrading the oldest known version, got %v", err)
+	}
+}
+
+func TestDowngradePerformance(t *testing.T) {
+	memory := new(PatchMemory)
+	helperLoadFromSysex(t, memory, validPerformanceSysex(t))
+	performance, _ := memory.GetPerformance(MemoryLocation{validPerformanceBank, validPerformanceLocation})
+	performance.Upgrade()
+	slot := performance.data.Patch_data_b
+
+	if err := performance.Downgrade(); err != nil {
+		t.Fatalf("Unexpected error downgrading performance: %s", err)
+	}
+	if performance.Version() != 1.18 || performance.data.Version_number != 118 {
+		t.Errorf("Expected downgraded version 1.18, got %1.2f (%d)", performance.Version(), performance.data.Version_number)
+	}
+	if performance.data.Patch_data_b != slot || performance.data.Patch_data_b.Version_number != 0 {
+		t.Errorf("Expected the slot programs to be kept as they were, without a version number")
+	}
+}
+
+func TestUnknownVersionsAreNotConverted(t *testing.T) {
+	program := NewInitProgram()
+	program.setVersion(1.22)
+
+	if err := program.Downgrade(); err != ErrUnsupportedVersion {
+		t.Errorf("Expected ErrUnsupportedVersion downgrading v1.22, got %v", err)
+	}
+	if err := program.Upgrade(); err != nil || program.Version() != 1.22 {
+		t.Errorf("Expected v1.22 to be left as it is by Upgrade, got %1.2f (%v)", program.Version(), err)
+	}
+}